@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+	"github.com/adil-chbada/extract-cli/pkg/extract"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	setValues   []string
+	showOrigins bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the effective configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration after all overrides",
+	Long: `Print the configuration generate would use, after applying the config file,
+the selected profile, EXTRACT_* environment variables and --set flags.
+
+Every config key can be overridden, with increasing precedence:
+
+1. Built-in defaults (and the common template when no config file exists)
+2. The config file
+3. The profile selected with --profile
+4. Environment variables named EXTRACT_ followed by the upper-cased key,
+   e.g. EXTRACT_PROJECT_PATH or EXTRACT_MAX_FILE_SIZE
+5. --set key=value flags
+6. Dedicated flags such as --strict or --output-dir
+
+Lists are written as comma separated values (EXTRACT_DATA_PATTERNS="data/**,**/*.csv")
+or as YAML flow sequences; maps and workspaces as YAML.
+
+Use --origin to annotate every key with the layer that set it.`,
+	Example: `  extract-cli config show
+  extract-cli config show --origin
+  EXTRACT_PROJECT_NAME=ci extract-cli config show --set max_file_size=1MB --origin`,
+	Args: cobra.NoArgs,
+	RunE: runConfigShow,
+}
+
+func init() {
+	configShowCmd.Flags().StringVarP(&configPath, "config", "c", "", "path to config file (if not specified, searches for default config files)")
+	configShowCmd.Flags().StringVarP(&profileName, "profile", "p", "", "name of a profile defined in the config")
+	configShowCmd.Flags().StringArrayVar(&setValues, "set", nil, "override a config key, e.g. --set project_name=api (repeatable)")
+	configShowCmd.Flags().BoolVar(&showOrigins, "origin", false, "show where each value came from")
+
+	configShowCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	configShowCmd.RegisterFlagCompletionFunc("set", completeConfigKeys)
+
+	configCmd.AddCommand(configShowCmd)
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(cfg); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if showOrigins {
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			key := doc.Content[i]
+			key.LineComment = cfg.Origin(key.Value).String()
+		}
+	}
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	return encoder.Close()
+}
+
+// loadConfig loads the config given with -c or found in the current directory and
+// layers the selected profile, EXTRACT_* environment variables, --set flags and
+// finally the given command specific layers on top of it, before the defaults are
+// applied and the result is validated. Without a config file the defaults of the
+// common template are used.
+func loadConfig(layers ...func(*config.Config)) (*config.Config, error) {
+	cfg, err := readConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if profileName != "" {
+		if err := cfg.ApplyProfile(profileName); err != nil {
+			logError(err.Error())
+			return nil, &configError{err: err}
+		}
+		logInfo(fmt.Sprintf("Using profile: %s", profileName))
+	}
+
+	if err := cfg.ApplyEnv(os.Environ()); err != nil {
+		logError(err.Error())
+		return nil, &configError{err: err}
+	}
+
+	for _, assignment := range setValues {
+		key, value, err := config.ParseAssignment(assignment)
+		if err == nil {
+			err = cfg.Set(key, value, config.Origin{Source: config.OriginFlag, Name: "--set"})
+		}
+		if err != nil {
+			logError(err.Error())
+			return nil, &usageError{err: err}
+		}
+	}
+
+	for _, layer := range layers {
+		layer(cfg)
+	}
+
+	if err := cfg.ApplyDefaults(); err != nil {
+		logError(err.Error())
+		return nil, &configError{err: err}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		err = fmt.Errorf("invalid config: %w", err)
+		logError(err.Error())
+		return nil, &configError{err: err}
+	}
+
+	return cfg, nil
+}
+
+// readConfig parses the config file without applying defaults
+func readConfig() (*config.Config, error) {
+	path := configPath
+	if path == "" {
+		found, err := findDefaultConfig()
+		if err != nil {
+			logInfo("No config file found, using the common template defaults")
+			return defaultConfig()
+		}
+		path = found
+	}
+
+	logInfo(fmt.Sprintf("Loading config from: %s", path))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		err = fmt.Errorf("failed to read config file: %w", err)
+		logError(fmt.Sprintf("Failed to load config: %v", err))
+		return nil, &configError{err: err}
+	}
+
+	cfg, err := config.Parse(data, path)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to load config: %v", err))
+		return nil, &configError{err: err}
+	}
+	return cfg, nil
+}
+
+// defaultConfig builds a config for the current directory from the common template
+func defaultConfig() (*config.Config, error) {
+	cfg, err := loadTemplateConfig("common")
+	if err != nil {
+		logError(fmt.Sprintf("Failed to load the common template: %v", err))
+		return nil, &configError{err: err}
+	}
+
+	cfg.ProjectName = projectNameFromDir(".")
+	cfg.ProjectPath = "."
+	return cfg, nil
+}
+
+// completeConfigKeys completes the keys accepted by --set
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if strings.Contains(toComplete, "=") {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var keys []string
+	for _, key := range config.Keys() {
+		keys = append(keys, key+"=")
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// Default config file names to search for (in order of preference)
+var defaultConfigFiles = []string{
+	"extract.config.yml",
+	"extract.config.yaml",
+	"extract-config.yaml",
+	"extract-config.yml",
+	".extract-config.yaml",
+	".extract-config.yml",
+	"extract.yaml",
+	"extract.yml",
+}
+
+// findDefaultConfig searches for default config files in the current directory
+func findDefaultConfig() (string, error) {
+	for _, filename := range defaultConfigFiles {
+		if _, err := os.Stat(filename); err == nil {
+			return filename, nil
+		}
+	}
+	return "", fmt.Errorf("no default config file found")
+}
+
+// completeProfiles completes the profile names of the selected or default config
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	path := configPath
+	if path == "" {
+		found, err := findDefaultConfig()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		path = found
+	}
+
+	cfg, err := extract.LoadConfig(path)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, name := range cfg.ProfileNames() {
+		if description := cfg.Profiles[name].Description; description != "" {
+			names = append(names, name+"\t"+description)
+		} else {
+			names = append(names, name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
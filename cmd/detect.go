@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+	"github.com/adil-chbada/extract-cli/internal/detect"
+	"gopkg.in/yaml.v3"
+)
+
+// detectTemplates inspects the current directory and returns the detected templates.
+// Falls back to the common template when nothing is recognised.
+func detectTemplates() ([]detect.Match, error) {
+	matches, err := detect.Detect(".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect project type: %w", err)
+	}
+
+	if len(matches) == 0 {
+		return []detect.Match{{Template: "common", Dir: ".", Reason: "no known project markers"}}, nil
+	}
+
+	return matches, nil
+}
+
+// buildDetectedConfig merges the templates of all matches into a single config.
+// Templates detected in subdirectories get their patterns prefixed with that directory.
+func buildDetectedConfig(matches []detect.Match) (*config.Config, error) {
+	cfg := &config.Config{
+		ProjectName: projectNameFromDir("."),
+		ProjectPath: ".",
+	}
+
+	for _, match := range matches {
+		tmpl, err := loadTemplateConfig(match.Template)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Merge(tmpl, match.Dir)
+	}
+
+	return cfg, nil
+}
+
+// loadTemplateConfig parses a template into a config without applying defaults
+func loadTemplateConfig(name string) (*config.Config, error) {
+	data, _, err := findTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config.Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse template '%s': %w", name, err)
+	}
+
+	return &cfg, nil
+}
+
+// renderConfig marshals a config to YAML with a descriptive header comment. Keys
+// the detected templates did not set are left out, so they keep their defaults.
+func renderConfig(cfg *config.Config, header string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for _, line := range strings.Split(strings.TrimSpace(header), "\n") {
+		fmt.Fprintf(&buf, "# %s\n", line)
+	}
+	buf.WriteString("\n")
+
+	var doc yaml.Node
+	if err := doc.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	unset := map[string]bool{
+		"data_patterns":    cfg.DataPatterns == nil,
+		"local_patterns":   cfg.LocalPatterns == nil,
+		"exclude_patterns": cfg.ExcludePatterns == nil,
+		"main_local_files": cfg.MainLocalFiles == nil,
+		"use_regex":        !cfg.UseRegex,
+	}
+	var content []*yaml.Node
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if !unset[doc.Content[i].Value] {
+			content = append(content, doc.Content[i], doc.Content[i+1])
+		}
+	}
+	doc.Content = content
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// describeMatches builds a human readable summary of detected templates
+func describeMatches(matches []detect.Match) string {
+	var parts []string
+	for _, match := range matches {
+		if match.Dir == "." {
+			parts = append(parts, match.Template)
+		} else {
+			parts = append(parts, fmt.Sprintf("%s in %s/", match.Template, match.Dir))
+		}
+	}
+	return strings.Join(parts, " + ")
+}
+
+// projectNameFromDir returns the base name of a directory for use as project name
+func projectNameFromDir(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "Project"
+	}
+	if name := filepath.Base(abs); name != string(os.PathSeparator) {
+		return name
+	}
+	return "Project"
+}
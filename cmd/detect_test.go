@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderConfigOmitsUnsetKeys(t *testing.T) {
+	cfg := &config.Config{
+		ProjectName:     "app",
+		ProjectPath:     ".",
+		ExcludePatterns: []string{"vendor/**"},
+		LocalPatterns:   []string{},
+	}
+	data, err := renderConfig(cfg, "Generated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+
+	for _, unset := range []string{"main_local_files", "data_patterns", "use_regex"} {
+		if strings.Contains(text, unset) {
+			t.Errorf("rendered config sets %s:\n%s", unset, text)
+		}
+	}
+	// An empty list was set on purpose and disables the defaults
+	if !strings.Contains(text, "local_patterns: []") {
+		t.Errorf("rendered config lost the empty local_patterns:\n%s", text)
+	}
+
+	var loaded config.Config
+	if err := yaml.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.ApplyDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.MainLocalFiles) == 0 {
+		t.Errorf("main_local_files defaults were not applied to the rendered config")
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Exit codes returned by the CLI
+const (
+	ExitOK     = 0 // Success
+	ExitError  = 1 // Generic failure while scanning or writing
+	ExitUsage  = 2 // Invalid flags or arguments
+	ExitConfig = 3 // Missing or invalid config file
+	// Interrupted runs exit with 128 + signal number (130 for SIGINT, 143 for SIGTERM)
+)
+
+// InterruptedError is returned when a run was stopped by a signal
+type InterruptedError struct {
+	Signal os.Signal
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("interrupted by %s", e.Signal)
+}
+
+// usageError marks errors caused by invalid command line usage
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// configError marks errors caused by a missing or invalid config file
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string { return e.err.Error() }
+func (e *configError) Unwrap() error { return e.err }
+
+// ExitCode maps an error returned by Execute to a process exit code
+func ExitCode(err error) int {
+	var (
+		interrupted *InterruptedError
+		usage       *usageError
+		cfgErr      *configError
+	)
+
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &interrupted):
+		if sig, ok := interrupted.Signal.(syscall.Signal); ok {
+			return 128 + int(sig)
+		}
+		return 128 + int(syscall.SIGINT)
+	case errors.As(err, &usage):
+		return ExitUsage
+	case errors.As(err, &cfgErr):
+		return ExitConfig
+	default:
+		return ExitError
+	}
+}
+
+// signalContext returns a context that is cancelled with an InterruptedError on
+// SIGINT or SIGTERM. After the first signal the default handlers are restored so a
+// second Ctrl-C terminates immediately.
+func signalContext(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			logWarn(fmt.Sprintf("Received %s, cleaning up...", sig))
+			cancel(&InterruptedError{Signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
@@ -2,34 +2,37 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/adil-chbada/extract-cli/internal/config"
-	"github.com/adil-chbada/extract-cli/internal/markdown"
-	"github.com/adil-chbada/extract-cli/internal/scanner"
+	"github.com/adil-chbada/extract-cli/pkg/extract"
 )
 
 var (
-	configPath string
-	outputDir  string
+	configPath   string
+	outputDir    string
+	strictScan   bool
+	reportErrors bool
+	wsOutput     string
+	profileName  string
+	outputFormat string
+
+	treeMode     string
+	templateName string
+	noProgress   bool
+	statsJSON    string
+
+	includePatterns []string
+	excludePatterns []string
+	dataPatterns    []string
+	localPatterns   []string
+	outlinePatterns []string
 )
 
-// Default config file names to search for (in order of preference)
-var defaultConfigFiles = []string{
-	"extract.config.yml",
-	"extract.config.yaml",
-	"extract-config.yaml",
-	"extract-config.yml",
-	".extract-config.yaml",
-	".extract-config.yml",
-	"extract.yaml",
-	"extract.yml",
-}
-
 var generateCmd = &cobra.Command{
-	Use:   "generate",
+	Use:   "generate [paths...]",
 	Short: "Generate markdown files from project based on config",
 	Long: `Scan your project directory and generate three markdown files:
 - project-code.md: All code files and main local files
@@ -38,128 +41,184 @@ var generateCmd = &cobra.Command{
 
 The tool respects .gitignore patterns and custom exclude patterns from your config.
 
+Files and directories that cannot be read (permission denied, broken mounts) are
+skipped and reported in the summary. Use --report-errors to also list them in
+project-code.md, or --strict to abort on the first unreadable path.
+
+Profiles defined in the config adjust the patterns, output directory and format
+for a particular kind of extraction, e.g. --profile api. The consolidated format
+writes a single project.md with a section per category.
+
+Configs with workspaces (monorepos) get one set of documents per workspace in a
+subdirectory of the output directory, or a single set of documents with a section
+per workspace when workspace_output is "combined".
+
+Files matched by context_files (e.g. README.md, docs/**) are kept out of the
+categories and rendered verbatim at the top of project-code.md, so assistants read
+them as prose. context_go_api adds the exported API of the Go packages.
+
+Go files matching outline_patterns (or --outline), or in a category listed in
+outline_categories, are embedded as outlines: package docs, exported declarations,
+signatures and doc comments, with function bodies elided. outline_unexported keeps
+the unexported declarations.
+
+With --tree (or the tree config key) documents start with an ASCII tree of their
+files; tree_depth, tree_max_files and tree_sizes control its depth, how many files
+are listed per directory and whether sizes are shown.
+
+--template (or the template config key) selects the layout of the documents: one
+of the built-in presets (default, minimal, detailed, claude-xml, github) or the
+path of a Go text/template file.
+
+Paths given as arguments limit the scan to those directories. The --include,
+--exclude, --data and --local flags add patterns to the loaded config and can be
+repeated.
+
 If no config file is specified, the tool will automatically search for default
 config files in the following order: extract.config.yml, extract.config.yaml,
 extract-config.yaml, extract-config.yml, .extract-config.yaml, .extract-config.yml,
-extract.yaml, extract.yml`,
+extract.yaml, extract.yml. When none exists, the defaults of the common template
+are used for the current directory.
+
+Progress is shown as a spinner when stderr is a terminal, and logged every few
+seconds otherwise (visible with -v).
+
+--stats-json writes file, byte and estimated token counts per category, directory
+and extension, the number of files excluded by each rule, the run duration and a
+hash of the effective config as JSON. Use "-" to write them to stdout; the summary
+is then printed to stderr.
+
+Any config key can be overridden with an EXTRACT_* environment variable or with
+--set key=value; see 'extract-cli config show --help' for the precedence rules.`,
 	Example: `  extract-cli generate
   extract-cli generate -c config.yaml
   extract-cli generate -c flutter-config.yaml -o ./output
-  extract-cli generate --config myproject.yaml --output-dir ./docs`,
+  extract-cli generate --config myproject.yaml --output-dir ./docs
+  extract-cli generate --workspace-output combined
+  extract-cli generate --profile backend --format consolidated
+  extract-cli generate --tree all --set tree_depth=2
+  extract-cli generate --template claude-xml --set include_contents=true
+  extract-cli generate src/api internal/auth
+  extract-cli generate --include "**/*.go" --exclude "**/*_test.go"
+  extract-cli generate --set include_contents=true --outline "internal/**"
+  extract-cli generate --set project_name=api --set max_file_size=256KB
+  extract-cli generate -q --stats-json stats.json`,
 	RunE: runGenerate,
 }
 
 func init() {
 	generateCmd.Flags().StringVarP(&configPath, "config", "c", "", "path to config file (if not specified, searches for default config files)")
-	generateCmd.Flags().StringVarP(&outputDir, "output-dir", "o", ".", "output directory for markdown files")
+	generateCmd.Flags().StringVarP(&outputDir, "output-dir", "o", ".", "output directory for markdown files (overrides output_dir)")
+	generateCmd.Flags().BoolVar(&strictScan, "strict", false, "abort on the first unreadable file or directory")
+	generateCmd.Flags().BoolVar(&reportErrors, "report-errors", false, "add a section listing unreadable paths to project-code.md")
+	generateCmd.Flags().StringVar(&wsOutput, "workspace-output", "", "how workspaces are written: separate or combined (overrides workspace_output)")
+	generateCmd.Flags().StringVarP(&profileName, "profile", "p", "", "name of a profile defined in the config")
+	generateCmd.Flags().StringVar(&outputFormat, "format", "", "output format: split or consolidated (overrides format)")
+
+	generateCmd.Flags().StringArrayVar(&includePatterns, "include", nil, "only list files matching this pattern (repeatable)")
+	generateCmd.Flags().StringArrayVar(&excludePatterns, "exclude", nil, "exclude files matching this pattern (repeatable)")
+	generateCmd.Flags().StringArrayVar(&dataPatterns, "data", nil, "treat files matching this pattern as data files (repeatable)")
+	generateCmd.Flags().StringArrayVar(&localPatterns, "local", nil, "treat files matching this pattern as local files (repeatable)")
+	generateCmd.Flags().StringArrayVar(&outlinePatterns, "outline", nil, "embed Go files matching this pattern as outlines without function bodies (repeatable)")
+	generateCmd.Flags().StringVar(&treeMode, "tree", "", "project tree at the top of the documents: none, all or consolidated (overrides tree)")
+	generateCmd.Flags().StringVar(&templateName, "template", "", "output template: a preset or the path of a text/template file (overrides template)")
+	generateCmd.Flags().BoolVar(&noProgress, "no-progress", false, "do not show scan progress")
+	generateCmd.Flags().StringVar(&statsJSON, "stats-json", "", "write run statistics as JSON to this file (- for stdout)")
+	generateCmd.Flags().StringArrayVar(&setValues, "set", nil, "override a config key, e.g. --set project_name=api (repeatable)")
+
+	generateCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	generateCmd.RegisterFlagCompletionFunc("set", completeConfigKeys)
+	generateCmd.RegisterFlagCompletionFunc("tree", cobra.FixedCompletions(
+		[]string{config.TreeNone, config.TreeAll, config.TreeConsolidated}, cobra.ShellCompDirectiveNoFileComp))
+	generateCmd.RegisterFlagCompletionFunc("template", cobra.FixedCompletions(config.TemplatePresets, cobra.ShellCompDirectiveDefault))
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
-	// If no config path specified, search for default config files
-	if configPath == "" {
-		foundConfig, err := findDefaultConfig()
-		if err != nil {
-			logError(fmt.Sprintf("No config file found. Please specify one with -c flag or create one of: %v", defaultConfigFiles))
-			return err
-		}
-		configPath = foundConfig
+	cfg, err := loadConfig(func(cfg *config.Config) { applyGenerateFlags(cmd, cfg) })
+	if err != nil {
+		return err
+	}
+
+	dir := outputDir
+	if !cmd.Flags().Changed("output-dir") && cfg.OutputDir != "" {
+		dir = cfg.OutputDir
 	}
 
-	logInfo(fmt.Sprintf("Loading config from: %s", configPath))
+	logInfo(fmt.Sprintf("Scanning project directory: %s", cfg.ProjectPath))
+
+	opts := []extract.Option{extract.WithPaths(args...)}
+	progress := newProgressReporter(noProgress)
+	if progress != nil {
+		opts = append(opts, extract.WithProgress(progress.update))
+	}
 
-	cfg, err := config.LoadConfig(configPath)
+	extractor, err := extract.New(cfg, opts...)
 	if err != nil {
-		logError(fmt.Sprintf("Failed to load config: %v", err))
+		logError(err.Error())
+		if len(args) > 0 {
+			return &usageError{err: err}
+		}
 		return err
 	}
 
-	logInfo(fmt.Sprintf("Scanning project directory: %s", cfg.ProjectPath))
-
-	result, err := scanner.Scan(cfg.ProjectPath, cfg)
+	summary, err := extractor.Generate(cmd.Context(), dir)
+	progress.finish()
 	if err != nil {
-		logError(fmt.Sprintf("Failed to scan project: %v", err))
+		if cmd.Context().Err() != nil {
+			logWarn("Generation cancelled; existing markdown files were left untouched")
+			return err
+		}
+		logError(fmt.Sprintf("Failed to generate markdown files: %v", err))
 		return err
 	}
 
-	// Write markdown files
-	files := map[string]struct {
-		title string
-		items []string
-	}{
-		"project-code.md": {
-			title: "Project Code Files",
-			items: result.Code,
-		},
-		"project-data.md": {
-			title: "Project Data Files",
-			items: result.Data,
-		},
-		"project-locals.md": {
-			title: "Project Local Files",
-			items: result.Locals,
-		},
+	for _, output := range summary.Outputs {
+		args := []any{"path", output.Path, "category", string(output.Category), "files", output.Files, "bytes", output.Size}
+		if output.Workspace != "" {
+			args = append(args, "workspace", output.Workspace)
+		}
+		logInfo("Wrote document", args...)
 	}
 
-	for filename, data := range files {
-		outputPath := filepath.Join(outputDir, filename)
-		logInfo(fmt.Sprintf("Writing %s (%d files)", outputPath, len(data.items)))
+	// Stats written to stdout must stay parseable, so the summary goes to stderr
+	out := io.Writer(os.Stdout)
+	if statsJSON == "-" {
+		out = os.Stderr
+	}
+	printSummary(out, summary, dir)
 
-		if err := markdown.WriteMarkdown(outputPath, data.title, data.items, cfg); err != nil {
-			logError(fmt.Sprintf("Failed to write %s: %v", filename, err))
+	if statsJSON != "" {
+		if err := writeStats(extractor.Stats(summary), statsJSON); err != nil {
+			logError(err.Error())
 			return err
 		}
 	}
 
-	// Calculate total sizes for each category
-	codeSize := calculateTotalSize(result.Code, cfg.ProjectPath)
-	dataSize := calculateTotalSize(result.Data, cfg.ProjectPath)
-	localsSize := calculateTotalSize(result.Locals, cfg.ProjectPath)
-	totalSize := codeSize + dataSize + localsSize
-	
-	// Print summary
-	fmt.Printf("\n%s\n", successColor("✓ Generation completed successfully!"))
-	fmt.Printf("Total files scanned: %d (%s)\n", result.Total, formatFileSize(totalSize))
-	fmt.Printf("├─ Code files: %d (%s)\n", len(result.Code), formatFileSize(codeSize))
-	fmt.Printf("├─ Data files: %d (%s)\n", len(result.Data), formatFileSize(dataSize))
-	fmt.Printf("├─ Local files: %d (%s)\n", len(result.Locals), formatFileSize(localsSize))
-	fmt.Printf("└─ Excluded files: %d\n", result.Excluded)
-	fmt.Printf("\nMarkdown files written to: %s\n", outputDir)
-
 	return nil
 }
 
-// formatFileSize formats file size in human readable format
-func formatFileSize(size int64) string {
-	const unit = 1024
-	if size < unit {
-		return fmt.Sprintf("%d B", size)
-	}
-	div, exp := int64(unit), 0
-	for n := size / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
-}
-
-// calculateTotalSize calculates the total size of a list of files
-func calculateTotalSize(files []string, projectPath string) int64 {
-	totalSize := int64(0)
-	for _, filePath := range files {
-		info, err := os.Stat(filepath.Join(projectPath, filePath))
-		if err == nil {
-			totalSize += info.Size()
+// applyGenerateFlags applies the dedicated generate flags, which take precedence over
+// every config layer. They are applied before the defaults, so the patterns they add
+// are validated like the ones of the config file.
+func applyGenerateFlags(cmd *cobra.Command, cfg *config.Config) {
+	flags := cmd.Flags()
+	set := func(name, key string, apply func()) {
+		if flags.Changed(name) {
+			apply()
+			cfg.SetOrigin(key, config.Origin{Source: config.OriginFlag, Name: "--" + name})
 		}
 	}
-	return totalSize
-}
 
-// findDefaultConfig searches for default config files in the current directory
-func findDefaultConfig() (string, error) {
-	for _, filename := range defaultConfigFiles {
-		if _, err := os.Stat(filename); err == nil {
-			return filename, nil
-		}
-	}
-	return "", fmt.Errorf("no default config file found")
-}
\ No newline at end of file
+	set("strict", "strict", func() { cfg.Strict = strictScan })
+	set("report-errors", "report_errors", func() { cfg.ReportErrors = reportErrors })
+	set("workspace-output", "workspace_output", func() { cfg.WorkspaceOutput = wsOutput })
+	set("format", "format", func() { cfg.Format = outputFormat })
+	set("tree", "tree", func() { cfg.Tree = treeMode })
+	set("template", "template", func() { cfg.Template = templateName })
+	set("output-dir", "output_dir", func() { cfg.OutputDir = outputDir })
+	set("include", "include_patterns", func() { cfg.IncludePatterns = append(cfg.IncludePatterns, includePatterns...) })
+	set("exclude", "exclude_patterns", func() { cfg.ExcludePatterns = append(cfg.ExcludePatterns, excludePatterns...) })
+	set("data", "data_patterns", func() { cfg.DataPatterns = append(cfg.DataPatterns, dataPatterns...) })
+	set("local", "local_patterns", func() { cfg.LocalPatterns = append(cfg.LocalPatterns, localPatterns...) })
+	set("outline", "outline_patterns", func() { cfg.OutlinePatterns = append(cfg.OutlinePatterns, outlinePatterns...) })
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// execute runs extract-cli with args in dir. The flags of every command are reset
+// once the test is done.
+func execute(t *testing.T, dir string, args ...string) error {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		resetFlags(rootCmd)
+	})
+
+	rootCmd.SetArgs(args)
+	return rootCmd.ExecuteContext(context.Background())
+}
+
+// resetFlags restores the default values of the flags of cmd and its subcommands
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// writeTree creates files in dir
+func writeTree(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readOutput returns a generated document
+func readOutput(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	fn()
+	w.Close()
+	return <-out
+}
+
+func TestGenerateStatsJSONToStdout(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, "main.go", "data/users.json")
+
+	var err error
+	stdout := captureStdout(t, func() {
+		err = execute(t, dir, "generate", "--no-progress", "-o", "out", "--stats-json", "-")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stats map[string]any
+	if err := json.Unmarshal([]byte(stdout), &stats); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, stdout)
+	}
+	if _, ok := stats["total"]; !ok {
+		t.Errorf("stats are missing the totals:\n%s", stdout)
+	}
+}
+
+func TestGeneratePatternFlagsWithoutConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, "main.go", "main_test.go", "fixtures/users.json", "app.toml")
+
+	err := execute(t, dir, "generate", "-q", "--no-progress", "-o", "out",
+		"--exclude", "**/*_test.go", "--data", "fixtures/**", "--local", "*.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := readOutput(t, filepath.Join(dir, "out", "project-code.md"))
+	if !strings.Contains(code, "`main.go`") || strings.Contains(code, "main_test.go") {
+		t.Errorf("project-code.md should list main.go without main_test.go:\n%s", code)
+	}
+	if data := readOutput(t, filepath.Join(dir, "out", "project-data.md")); !strings.Contains(data, "`users.json`") {
+		t.Errorf("project-data.md should list fixtures/users.json:\n%s", data)
+	}
+	if locals := readOutput(t, filepath.Join(dir, "out", "project-locals.md")); !strings.Contains(locals, "`app.toml`") {
+		t.Errorf("project-locals.md should list app.toml:\n%s", locals)
+	}
+}
+
+func TestGeneratePatternFlagsWithSubtreePaths(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, "services/api/main.go", "services/api/README.md", "services/api/data/seed.json", "web/app.go")
+	if err := os.WriteFile(filepath.Join(dir, "extract.config.yml"), []byte("project_path: .\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := execute(t, dir, "generate", "-q", "--no-progress", "-o", "out",
+		"--include", "services/api/**/*.go", "--include", "services/api/data/**",
+		"--data", "services/api/data/**", "services/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := readOutput(t, filepath.Join(dir, "out", "project-code.md"))
+	if !strings.Contains(code, "`services/api/main.go`") {
+		t.Errorf("project-code.md should list services/api/main.go:\n%s", code)
+	}
+	for _, unwanted := range []string{"README.md", "app.go", "seed.json"} {
+		if strings.Contains(code, unwanted) {
+			t.Errorf("project-code.md should not list %s:\n%s", unwanted, code)
+		}
+	}
+	if data := readOutput(t, filepath.Join(dir, "out", "project-data.md")); !strings.Contains(data, "`services/api/data/seed.json`") {
+		t.Errorf("project-data.md should list services/api/data/seed.json:\n%s", data)
+	}
+}
+
+func TestGenerateValidatesPatternFlags(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, "main.go")
+
+	err := execute(t, dir, "generate", "-q", "--no-progress", "-o", "out", "--exclude", "[abc")
+	var cfgErr *configError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("error = %v, want a config error", err)
+	}
+	if !strings.Contains(err.Error(), `invalid exclude_patterns pattern "[abc"`) {
+		t.Errorf("error = %v, want it to name the pattern", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out")); !os.IsNotExist(err) {
+		t.Errorf("no output should be written for an invalid pattern")
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
 	"os"
@@ -8,6 +9,9 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/adil-chbada/extract-cli/internal/atomicfile"
+	"github.com/adil-chbada/extract-cli/internal/config"
+	"github.com/adil-chbada/extract-cli/internal/diff"
 )
 
 //go:embed templates/*.yaml
@@ -16,6 +20,11 @@ var templatesFS embed.FS
 var (
 	outputFile string
 	listTemplates bool
+	detectProject bool
+	interactive   bool
+	forceInit     bool
+	diffInit      bool
+	mergeInit     bool
 )
 
 var initCmd = &cobra.Command{
@@ -31,19 +40,61 @@ Available templates:
 - vue: Vue.js projects with node_modules/, dist/ exclusions
 - react: React projects with build/, node_modules/ exclusions
 - nodejs: Node.js projects with standard npm exclusions
-- python: Python projects with __pycache__/, .pyc exclusions`,
+- python: Python projects with __pycache__/, .pyc exclusions
+- rust: Rust (Cargo) projects with target/ exclusions
+- gradle: Java/Kotlin Gradle projects with build/, .gradle/ exclusions
+- maven: Java/Kotlin Maven projects with target/ exclusions
+- dotnet: .NET projects with bin/, obj/ exclusions
+- swift: Swift/iOS projects with Pods/, DerivedData/ exclusions
+- android: Android projects with build/, .gradle/ exclusions
+- nextjs: Next.js projects with .next/, node_modules/ exclusions
+- django: Django projects with migrations as data and staticfiles/ exclusions
+- rails: Ruby on Rails projects with log/, tmp/, storage/ exclusions
+- terraform: Terraform/IaC projects with .terraform/, *.tfstate exclusions
+- elixir: Elixir/Phoenix projects with _build/, deps/ exclusions
+- cmake: C/C++ CMake projects with build/, object file exclusions
+
+Templates are also loaded from $EXTRACT_CLI_TEMPLATE_PATH, from
+~/.config/extract-cli/templates and from sources registered with
+'extract-cli init template add'. Built-in templates are the fallback.
+
+Use --detect to pick templates automatically from project markers (go.mod,
+Cargo.toml, pubspec.yaml, composer.json + artisan, package.json dependencies,
+pyproject.toml, manage.py, pom.xml, build.gradle, *.csproj, *.tf, mix.exs,
+CMakeLists.txt, ...).
+Templates detected in subdirectories are merged with their patterns prefixed by
+that directory, e.g. a Go backend with a React frontend in web/.
+
+Use -i to run an interactive wizard that asks for the project name, offers the
+detected templates, previews how many files land in each category and lets you
+toggle top-level directories in and out before writing the config.
+
+An existing config file is never overwritten unless --force is given. Use --diff
+to preview the changes and --merge to add the template patterns missing from the
+current config while keeping its comments and ordering.`,
 	Example: `  extract-cli init common
 extract-cli init go
 extract-cli init go -o my-go-config.yaml
 extract-cli init flutter -o my-flutter-config.yaml
 extract-cli init react --output my-react-config.yaml
-extract-cli init --list`,
+extract-cli init --detect
+extract-cli init -i
+extract-cli init go --merge --diff
+extract-cli init go --merge
+extract-cli init --list
+extract-cli init template add https://github.com/acme/extract-templates.git`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if listTemplates {
 			return nil
 		}
+		if detectProject || interactive {
+			if len(args) != 0 {
+				return &usageError{err: fmt.Errorf("--detect and --interactive do not take a template name")}
+			}
+			return nil
+		}
 		if len(args) != 1 {
-			return fmt.Errorf("requires exactly one template name")
+			return &usageError{err: fmt.Errorf("requires exactly one template name")}
 		}
 		return nil
 	},
@@ -53,6 +104,12 @@ extract-cli init --list`,
 func init() {
 	initCmd.Flags().StringVarP(&outputFile, "output", "o", "", "output file path (defaults to extract.config.yml)")
 	initCmd.Flags().BoolVarP(&listTemplates, "list", "l", false, "list available templates")
+	initCmd.Flags().BoolVarP(&detectProject, "detect", "d", false, "detect the project type and merge matching templates")
+	initCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "build the config with an interactive wizard")
+	initCmd.Flags().BoolVarP(&forceInit, "force", "f", false, "overwrite an existing config file")
+	initCmd.Flags().BoolVar(&diffInit, "diff", false, "show the changes against an existing config file without writing it")
+	initCmd.Flags().BoolVar(&mergeInit, "merge", false, "add template patterns missing from an existing config, keeping comments and ordering")
+	initCmd.MarkFlagsMutuallyExclusive("force", "merge")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
@@ -65,19 +122,82 @@ func runInit(cmd *cobra.Command, args []string) error {
 		outputFile = "extract.config.yml"
 	}
 
-	templateName := args[0]
-	templatePath := fmt.Sprintf("templates/%s.yaml", templateName)
+	var (
+		templateName string
+		templateData []byte
+		err          error
+	)
+
+	if interactive {
+		templateName, templateData, err = runWizard(cmd.Context())
+		if err != nil {
+			logError(err.Error())
+			return err
+		}
+	} else if detectProject {
+		templateName, templateData, err = detectTemplateData()
+		if err != nil {
+			logError(err.Error())
+			return err
+		}
+	} else {
+		templateName = args[0]
 
-	logInfo(fmt.Sprintf("Loading template: %s", templateName))
+		logInfo(fmt.Sprintf("Loading template: %s", templateName))
 
-	templateData, err := templatesFS.ReadFile(templatePath)
-	if err != nil {
-		logError(fmt.Sprintf("Template '%s' not found", templateName))
-		fmt.Println("\nAvailable templates:")
-		showAvailableTemplates()
+		var info templateInfo
+		templateData, info, err = findTemplate(templateName)
+		if err != nil {
+			logError(err.Error())
+			fmt.Println()
+			showAvailableTemplates()
+			return err
+		}
+
+		logInfo(fmt.Sprintf("Using template '%s' from %s (%s)", templateName, info.Source, info.Location))
+	}
+
+	existingData, err := os.ReadFile(outputFile)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		logError(fmt.Sprintf("Failed to read existing config: %v", err))
 		return err
 	}
 
+	if exists && mergeInit {
+		merged, err := config.MergeYAML(existingData, templateData)
+		if err != nil {
+			logError(fmt.Sprintf("Failed to merge config: %v", err))
+			return err
+		}
+		logInfo(fmt.Sprintf("Merging %d new entries into %s", merged.Added, outputFile))
+		if merged.Rewritten {
+			logWarn(fmt.Sprintf("%s is rewritten and its formatting normalized: %s", outputFile, merged.Reason))
+		}
+		templateData = merged.Data
+	}
+
+	if diffInit {
+		changes := diff.Unified(string(existingData), string(templateData), outputFile+" (current)", outputFile+" (new)")
+		if changes == "" {
+			logSuccess(fmt.Sprintf("No changes to %s", outputFile))
+			return nil
+		}
+		fmt.Print(colorizeDiff(changes))
+		return nil
+	}
+
+	if exists && !forceInit && !mergeInit {
+		err := fmt.Errorf("%s already exists", outputFile)
+		logError(fmt.Sprintf("Refusing to overwrite %s (use --force to overwrite, --merge to add missing patterns or --diff to preview)", outputFile))
+		return err
+	}
+
+	if exists && mergeInit && bytes.Equal(existingData, templateData) {
+		logSuccess(fmt.Sprintf("%s already contains all template patterns", outputFile))
+		return nil
+	}
+
 	// Create output directory if it doesn't exist
 	if dir := filepath.Dir(outputFile); dir != "." {
 		if err := os.MkdirAll(dir, 0755); err != nil {
@@ -88,32 +208,90 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	logInfo(fmt.Sprintf("Writing config to: %s", outputFile))
 
-	if err := os.WriteFile(outputFile, templateData, 0644); err != nil {
+	if err := atomicfile.WriteFile(outputFile, templateData); err != nil {
 		logError(fmt.Sprintf("Failed to write config file: %v", err))
 		return err
 	}
 
-	logSuccess(fmt.Sprintf("Config file created: %s", outputFile))
-	fmt.Printf("Template: %s\n", infoColor(templateName))
-	fmt.Printf("You can now edit the config and run: %s\n",
-		infoColor(fmt.Sprintf("extract-cli generate -c %s", outputFile)))
+	// Structured logs carry the template in the record instead of the hints below
+	var attrs []any
+	if structuredLogs() {
+		attrs = append(attrs, "path", outputFile, "template", templateName)
+	}
+	if exists && mergeInit {
+		logSuccess(fmt.Sprintf("Config file updated: %s", outputFile), attrs...)
+	} else {
+		logSuccess(fmt.Sprintf("Config file created: %s", outputFile), attrs...)
+	}
+	if !quiet && !structuredLogs() {
+		fmt.Printf("Template: %s\n", infoColor(templateName))
+		fmt.Printf("You can now edit the config and run: %s\n",
+			infoColor(fmt.Sprintf("extract-cli generate -c %s", outputFile)))
+	}
 
 	return nil
 }
 
+// detectTemplateData detects the project type and renders the merged config
+func detectTemplateData() (string, []byte, error) {
+	matches, err := detectTemplates()
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, match := range matches {
+		logInfo(fmt.Sprintf("Detected %s in %s (%s)", match.Template, match.Dir, match.Reason))
+	}
+
+	cfg, err := buildDetectedConfig(matches)
+	if err != nil {
+		return "", nil, err
+	}
+
+	summary := describeMatches(matches)
+	data, err := renderConfig(cfg, fmt.Sprintf("Generated by extract-cli init --detect\nDetected: %s", summary))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return summary, data, nil
+}
+
+// colorizeDiff colours added and removed lines of a unified diff
+func colorizeDiff(changes string) string {
+	lines := strings.SplitAfter(changes, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			lines[i] = successColor(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = errorColor(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = infoColor(line)
+		}
+	}
+	return strings.Join(lines, "")
+}
+
 func showAvailableTemplates() error {
-	entries, err := templatesFS.ReadDir("templates")
+	templates, err := allTemplates()
 	if err != nil {
 		return err
 	}
 
 	fmt.Println("Available templates:")
-	for _, entry := range entries {
-		if strings.HasSuffix(entry.Name(), ".yaml") {
-			templateName := strings.TrimSuffix(entry.Name(), ".yaml")
-			fmt.Printf("  - %s\n", infoColor(templateName))
+	for _, tmpl := range templates {
+		source := tmpl.Source
+		if tmpl.Source != "built-in" {
+			source = fmt.Sprintf("%s: %s", tmpl.Source, tmpl.Location)
+		}
+		if tmpl.Shadowed {
+			fmt.Printf("  - %-12s %s\n", tmpl.Name, warnColor(fmt.Sprintf("(%s, overridden)", source)))
+			continue
 		}
+		fmt.Printf("  - %-12s (%s)\n", infoColor(tmpl.Name), source)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
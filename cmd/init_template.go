@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var templateSourceName string
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage user and remote template sources",
+	Long: `Templates are looked up in the following order:
+
+1. Directories listed in $EXTRACT_CLI_TEMPLATE_PATH
+2. The user template directory (~/.config/extract-cli/templates)
+3. Sources registered with 'extract-cli init template add'
+4. Built-in templates
+
+A template found in an earlier location overrides one with the same name
+in a later location. Templates are *.yaml or *.yml files.
+
+Git repositories are cloned once when they are added; run
+'extract-cli init template update' to fetch their latest templates.`,
+}
+
+var templateAddCmd = &cobra.Command{
+	Use:   "add <file|directory|git-url>",
+	Short: "Add a template file, a template directory or a git repository",
+	Example: `  extract-cli init template add ./team-go.yaml
+  extract-cli init template add ~/src/org-templates
+  extract-cli init template add https://github.com/acme/extract-templates.git --name acme`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := addTemplateSource(args[0], templateSourceName)
+		if err != nil {
+			logError(err.Error())
+			return err
+		}
+
+		logSuccess(fmt.Sprintf("Template source added: %s", name))
+		return nil
+	},
+}
+
+var templateUpdateCmd = &cobra.Command{
+	Use:   "update [name]",
+	Short: "Fetch the latest templates of registered git sources",
+	Long: `Fetch the latest commit of every registered git source, or of the named one.
+Local directories are read in place and never need an update.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+
+		updated, err := updateTemplateSources(name)
+		if err != nil {
+			logError(err.Error())
+			return err
+		}
+
+		if len(updated) == 0 {
+			logInfo("No git template sources to update")
+			return nil
+		}
+		logSuccess(fmt.Sprintf("Template sources updated: %s", strings.Join(updated, ", ")))
+		return nil
+	},
+}
+
+var templateRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a registered template source or user template",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := removeTemplateSource(args[0]); err != nil {
+			logError(err.Error())
+			return err
+		}
+
+		logSuccess(fmt.Sprintf("Template source removed: %s", args[0]))
+		return nil
+	},
+}
+
+func init() {
+	templateAddCmd.Flags().StringVarP(&templateSourceName, "name", "n", "", "name of the source (defaults to the file, directory or repository name)")
+
+	templateCmd.AddCommand(templateAddCmd)
+	templateCmd.AddCommand(templateUpdateCmd)
+	templateCmd.AddCommand(templateRemoveCmd)
+	initCmd.AddCommand(templateCmd)
+}
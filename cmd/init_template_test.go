@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// userTemplates points the user config directory to a temporary directory and
+// returns its template directory
+func userTemplates(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir, err := userTemplatesDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRemoveTemplateSourceRejectsPaths(t *testing.T) {
+	dir := userTemplates(t)
+	victim := filepath.Join(filepath.Dir(filepath.Dir(dir)), "victim.yaml")
+	if err := os.WriteFile(victim, []byte("project_path: .\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"../../victim", "../victim", "..", "a/b", `a\b`, ""} {
+		if err := removeTemplateSource(name); err == nil {
+			t.Errorf("removeTemplateSource(%q) succeeded, want an invalid name error", name)
+		}
+	}
+	if _, err := os.Stat(victim); err != nil {
+		t.Errorf("file outside the template directory was removed: %v", err)
+	}
+}
+
+func TestYmlTemplates(t *testing.T) {
+	dir := userTemplates(t)
+	if err := os.WriteFile(filepath.Join(dir, "team.yml"), []byte("project_name: team\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, info, err := findTemplate("team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "project_name: team\n" || info.Source != "user" {
+		t.Errorf("findTemplate(team) = %q from %s, want the .yml user template", data, info.Source)
+	}
+
+	// A single .yml file is added as a template, not as a directory
+	file := filepath.Join(t.TempDir(), "other.yml")
+	if err := os.WriteFile(file, []byte("project_name: other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	name, err := addTemplateSource(file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "other" {
+		t.Errorf("addTemplateSource(%s) name = %q, want other", file, name)
+	}
+	if _, _, err := findTemplate("other"); err != nil {
+		t.Errorf("added .yml template not found: %v", err)
+	}
+
+	if err := removeTemplateSource("team"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "team.yml")); !os.IsNotExist(err) {
+		t.Errorf("team.yml was not removed")
+	}
+}
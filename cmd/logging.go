@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// levelSuccess reports a completed operation. It sits between info and warn so
+// that success messages are shown by default while info messages need -v.
+const levelSuccess = slog.LevelInfo + 2
+
+// Log formats
+const (
+	logFormatPretty = "pretty" // Colourized "[LEVEL] message" lines for humans
+	logFormatText   = "text"   // logfmt key=value lines
+	logFormatJSON   = "json"   // One JSON object per line
+)
+
+var (
+	logFormat string
+	logLevel  string
+	quiet     bool
+
+	// level is the minimum level of the logger, adjusted by the logging flags
+	level = func() *slog.LevelVar {
+		v := new(slog.LevelVar)
+		v.Set(levelSuccess)
+		return v
+	}()
+
+	logger = slog.New(newPrettyHandler(os.Stderr, level))
+)
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&logFormat, "log-format", logFormatPretty, "log format: pretty, text or json")
+	flags.StringVar(&logLevel, "log-level", "", "minimum log level: debug, info, success, warn or error (default success, info with -v)")
+	flags.BoolVarP(&quiet, "quiet", "q", false, "only log errors and skip the summary")
+
+	rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions(
+		[]string{logFormatPretty, logFormatText, logFormatJSON}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions(
+		[]string{"debug", "info", "success", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// setupLogging configures the logger from the logging flags. Colours of the pretty
+// format follow the NO_COLOR convention and are disabled when stderr, where logs are
+// written, is not a terminal.
+func setupLogging(cmd *cobra.Command) error {
+	switch {
+	case quiet:
+		level.Set(slog.LevelError)
+	case logLevel != "":
+		lvl, err := parseLevel(logLevel)
+		if err != nil {
+			return &usageError{err: err}
+		}
+		level.Set(lvl)
+	case verbose:
+		level.Set(slog.LevelInfo)
+	}
+
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: replaceLevel}
+	switch logFormat {
+	case logFormatPretty:
+		logger = slog.New(newPrettyHandler(os.Stderr, level))
+	case logFormatText:
+		logger = slog.New(slog.NewTextHandler(os.Stderr, options))
+	case logFormatJSON:
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, options))
+	default:
+		return &usageError{err: fmt.Errorf("invalid log format %q (expected pretty, text or json)", logFormat)}
+	}
+
+	// Plain "Error:" lines and usage output would break structured logs; Execute
+	// logs the final error instead
+	if structuredLogs() {
+		cmd.Root().SilenceErrors = true
+		cmd.Root().SilenceUsage = true
+	}
+
+	return nil
+}
+
+// structuredLogs reports whether logs are written for machines rather than humans
+func structuredLogs() bool {
+	return logFormat == logFormatText || logFormat == logFormatJSON
+}
+
+// parseLevel parses a level name
+func parseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "success":
+		return levelSuccess, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level %q (expected debug, info, success, warn or error)", name)
+	}
+}
+
+// levelName returns the display name of a level
+func levelName(lvl slog.Level) string {
+	if lvl == levelSuccess {
+		return "SUCCESS"
+	}
+	return lvl.String()
+}
+
+// replaceLevel names the success level in text and JSON logs
+func replaceLevel(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.LevelKey && len(groups) == 0 {
+		if lvl, ok := attr.Value.Any().(slog.Level); ok {
+			attr.Value = slog.StringValue(levelName(lvl))
+		}
+	}
+	return attr
+}
+
+// prettyHandler writes colourized "[LEVEL] message key=value" lines
+type prettyHandler struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	level  slog.Leveler
+	attrs  []slog.Attr
+	colour bool // Colourize the level tags
+}
+
+func newPrettyHandler(out io.Writer, level slog.Leveler) *prettyHandler {
+	return &prettyHandler{mu: &sync.Mutex{}, out: out, level: level, colour: colourOutput(out)}
+}
+
+// colourOutput reports whether colours are written to out. The colour functions
+// follow stdout, so the logs on stderr decide for themselves: out must be a
+// terminal and NO_COLOR must not be set.
+func colourOutput(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	if !ok || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+func (h *prettyHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return lvl >= h.level.Level()
+}
+
+func (h *prettyHandler) Handle(_ context.Context, record slog.Record) error {
+	var b strings.Builder
+	tag := "[" + levelName(record.Level) + "]"
+	if h.colour {
+		tag = levelColor(record.Level).Sprint(tag)
+	}
+	b.WriteString(tag)
+	b.WriteString(" ")
+	b.WriteString(record.Message)
+
+	writeAttr := func(attr slog.Attr) bool {
+		fmt.Fprintf(&b, " %s=%v", attr.Key, attr.Value)
+		return true
+	}
+	for _, attr := range h.attrs {
+		writeAttr(attr)
+	}
+	record.Attrs(writeAttr)
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.out, b.String())
+	return err
+}
+
+func (h *prettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr(nil), h.attrs...), attrs...)
+	return &clone
+}
+
+// WithGroup is not used by the CLI; groups are flattened into the line
+func (h *prettyHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// levelColor returns the colour of a level, enabled regardless of stdout
+func levelColor(lvl slog.Level) *color.Color {
+	var c *color.Color
+	switch {
+	case lvl >= slog.LevelError:
+		c = color.New(color.FgRed)
+	case lvl >= slog.LevelWarn:
+		c = color.New(color.FgYellow)
+	case lvl >= levelSuccess:
+		c = color.New(color.FgGreen)
+	default:
+		c = color.New(color.FgCyan)
+	}
+	c.EnableColor()
+	return c
+}
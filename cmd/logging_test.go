@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestPrettyHandlerColours(t *testing.T) {
+	// The global colour setting follows stdout; logs that are not written to a
+	// terminal stay plain even when stdout is one
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	var out strings.Builder
+	logger := slog.New(newPrettyHandler(&out, slog.LevelInfo))
+	logger.Log(context.Background(), slog.LevelWarn, "careful", "path", "a.go")
+	if got, want := out.String(), "[WARN] careful path=a.go\n"; got != want {
+		t.Errorf("log line = %q, want %q", got, want)
+	}
+
+	out.Reset()
+	handler := newPrettyHandler(&out, slog.LevelInfo)
+	handler.colour = true
+	slog.New(handler).Error("failed")
+	if !strings.Contains(out.String(), "\x1b[31m[ERROR]") {
+		t.Errorf("log line = %q, want a red level tag", out.String())
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adil-chbada/extract-cli/pkg/extract"
+	"github.com/mattn/go-isatty"
+)
+
+// Intervals between progress updates
+const (
+	spinnerInterval  = 100 * time.Millisecond
+	progressInterval = 2 * time.Second
+)
+
+// spinnerFrames are the frames of the terminal spinner
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// progressReporter shows progress events as a spinner line when stderr is a
+// terminal, and as periodic log lines otherwise
+type progressReporter struct {
+	out      io.Writer
+	spinner  bool
+	interval time.Duration
+	last     time.Time
+	frame    int
+	shown    bool // A spinner line is currently displayed
+}
+
+// newProgressReporter returns a reporter for the current output, or nil when
+// progress should not be shown
+func newProgressReporter(disabled bool) *progressReporter {
+	if disabled || quiet {
+		return nil
+	}
+
+	fd := os.Stderr.Fd()
+	if logFormat == logFormatPretty && (isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)) {
+		return &progressReporter{out: os.Stderr, spinner: true, interval: spinnerInterval}
+	}
+	return &progressReporter{out: os.Stderr, interval: progressInterval, last: time.Now()}
+}
+
+// update handles a progress event, throttled to the reporter interval
+func (r *progressReporter) update(p extract.Progress) {
+	now := time.Now()
+	if now.Sub(r.last) < r.interval {
+		return
+	}
+	r.last = now
+
+	if !r.spinner {
+		if p.Stage == extract.StageWrite {
+			logInfo("Writing documents", "written", p.Written, "documents", p.Documents)
+			return
+		}
+		logInfo("Scanning", "walked", p.Walked, "matched", p.Matched, "bytes", p.Bytes)
+		return
+	}
+
+	frame := spinnerFrames[r.frame%len(spinnerFrames)]
+	r.frame++
+
+	var line string
+	if p.Stage == extract.StageWrite {
+		line = fmt.Sprintf("%s Writing %d/%d %s", frame, p.Written, p.Documents, filepath.Base(p.Path))
+	} else {
+		line = fmt.Sprintf("%s Scanning: %d walked, %d matched (%s)", frame, p.Walked, p.Matched, formatFileSize(p.Bytes))
+	}
+	fmt.Fprintf(r.out, "\r\033[K%s", infoColor(line))
+	r.shown = true
+}
+
+// finish removes the spinner line
+func (r *progressReporter) finish() {
+	if r != nil && r.shown {
+		fmt.Fprint(r.out, "\r\033[K")
+		r.shown = false
+	}
+}
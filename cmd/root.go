@@ -1,8 +1,8 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
+	"context"
+	"errors"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -29,36 +29,59 @@ markdown files for code, data, and local files.
 It supports multiple project templates (Flutter, Laravel, Vue, React) and
 respects .gitignore patterns for intelligent file filtering.`,
 	Version: version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupLogging(cmd)
+	},
 }
 
+// Execute runs the root command. SIGINT and SIGTERM cancel the command context;
+// the returned error can be mapped to an exit code with ExitCode.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
+
+	var interrupted *InterruptedError
+	if err != nil && errors.As(context.Cause(ctx), &interrupted) {
+		err = interrupted
+	}
+
+	if err != nil && rootCmd.SilenceErrors {
+		logError("Command failed", "error", err.Error(), "exit_code", ExitCode(err))
+	}
+
+	return err
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
 
 	// Add subcommands
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(statsCmd)
 	rootCmd.AddCommand(completionCmd)
 }
 
-// Logging helpers
-func logInfo(msg string) {
-	if verbose {
-		fmt.Fprintf(os.Stderr, "%s %s\n", infoColor("[INFO]"), msg)
-	}
+// Logging helpers. Messages go through the slog logger configured by the
+// logging flags; args are optional key/value attributes.
+func logInfo(msg string, args ...any) {
+	logger.Info(msg, args...)
 }
 
-func logSuccess(msg string) {
-	fmt.Fprintf(os.Stderr, "%s %s\n", successColor("[SUCCESS]"), msg)
+func logSuccess(msg string, args ...any) {
+	logger.Log(context.Background(), levelSuccess, msg, args...)
 }
 
-func logWarn(msg string) {
-	fmt.Fprintf(os.Stderr, "%s %s\n", warnColor("[WARN]"), msg)
+func logWarn(msg string, args ...any) {
+	logger.Warn(msg, args...)
 }
 
-func logError(msg string) {
-	fmt.Fprintf(os.Stderr, "%s %s\n", errorColor("[ERROR]"), msg)
-}
\ No newline at end of file
+func logError(msg string, args ...any) {
+	logger.Error(msg, args...)
+}
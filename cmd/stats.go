@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/adil-chbada/extract-cli/pkg/extract"
+	"github.com/spf13/cobra"
+)
+
+// maxMixLanguages is the number of languages listed per directory before the
+// rest are folded into "other"
+const maxMixLanguages = 3
+
+var (
+	statsDepth  int
+	statsAsJSON bool
+	statsNoMix  bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [paths...]",
+	Short: "Show files, lines and size by language",
+	Long: `Scan the project with the same config as generate and break the listed files
+down by language, like a lightweight cloc.
+
+Languages are detected from well-known file names (Dockerfile, Makefile,
+Gemfile, ...), then from the file extension, then from the shebang line of
+scripts without an extension. Files that match none of them are counted as
+"Other".
+
+Lines are split into code, comment and blank lines using the comment syntax of
+each language; a line with both code and a comment counts as code. Comment
+markers inside string literals are not recognised, so the split is an estimate.
+
+The per-directory language mix groups files by their top-level directories;
+use --depth to look deeper. --json prints the same statistics as
+'generate --stats-json' without writing any markdown.`,
+	Example: `  extract-cli stats
+  extract-cli stats --depth 2
+  extract-cli stats -p backend services/api
+  extract-cli stats --json | jq '.languages'`,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().StringVarP(&configPath, "config", "c", "", "path to config file (if not specified, searches for default config files)")
+	statsCmd.Flags().StringVarP(&profileName, "profile", "p", "", "name of a profile defined in the config")
+	statsCmd.Flags().StringArrayVar(&setValues, "set", nil, "override a config key, e.g. --set project_name=api (repeatable)")
+	statsCmd.Flags().IntVar(&statsDepth, "depth", 1, "directory depth of the language mix (0 for every directory)")
+	statsCmd.Flags().BoolVar(&statsNoMix, "no-mix", false, "do not show the per-directory language mix")
+	statsCmd.Flags().BoolVar(&statsAsJSON, "json", false, "print the statistics as JSON")
+	statsCmd.Flags().BoolVar(&noProgress, "no-progress", false, "do not show scan progress")
+
+	statsCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	statsCmd.RegisterFlagCompletionFunc("set", completeConfigKeys)
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	if statsDepth < 0 {
+		return &usageError{err: fmt.Errorf("--depth must not be negative")}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	opts := []extract.Option{extract.WithPaths(args...)}
+	progress := newProgressReporter(noProgress)
+	if progress != nil {
+		opts = append(opts, extract.WithProgress(progress.update))
+	}
+
+	extractor, err := extract.New(cfg, opts...)
+	if err != nil {
+		logError(err.Error())
+		if len(args) > 0 {
+			return &usageError{err: err}
+		}
+		return err
+	}
+
+	result, err := extractor.Scan(cmd.Context())
+	progress.finish()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to scan project: %v", err))
+		return err
+	}
+
+	stats := extractor.ResultStats(result)
+	if statsAsJSON {
+		return writeStats(stats, "-")
+	}
+
+	printLanguages(stats)
+	if !statsNoMix {
+		printLanguageMix(stats.LanguageMixAt(statsDepth))
+	}
+	return nil
+}
+
+// printLanguages prints the language table
+func printLanguages(stats *extract.Stats) {
+	total := stats.Total
+	fmt.Printf("%s\n\n", successColor(fmt.Sprintf("Languages of %s: %d files, %d lines, %s",
+		stats.Project, total.Files, total.Lines.Total, formatFileSize(total.Bytes))))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Language\tFiles\tLines\tCode\tComment\tBlank\tSize\tShare")
+	for _, lang := range sortedLanguages(stats.Languages) {
+		counts := stats.Languages[lang]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\n",
+			lang, counts.Files, counts.Lines.Total, lineBreakdown(counts.Lines, "\t"),
+			formatFileSize(counts.Bytes), share(counts.Bytes, total.Bytes))
+	}
+	fmt.Fprintf(w, "Total\t%d\t%d\t%s\t%s\t%s\n",
+		total.Files, total.Lines.Total, lineBreakdown(total.Lines, "\t"), formatFileSize(total.Bytes), share(total.Bytes, total.Bytes))
+	w.Flush()
+}
+
+// lineBreakdown formats the code, comment and blank lines separated by sep, or
+// dashes for languages whose lines are not classified
+func lineBreakdown(lines extract.LineCounts, sep string) string {
+	if !lines.Classified() {
+		return strings.Join([]string{"-", "-", "-"}, sep)
+	}
+	return fmt.Sprintf("%d%s%d%s%d", lines.Code, sep, lines.Comment, sep, lines.Blank)
+}
+
+// printLanguageMix prints the main languages of every directory
+func printLanguageMix(mixes map[string]map[string]extract.Counts) {
+	if len(mixes) == 0 {
+		return
+	}
+
+	dirs := make([]string, 0, len(mixes))
+	for dir := range mixes {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	fmt.Printf("\n%s\n\n", successColor("Languages by directory"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, dir := range dirs {
+		mix := mixes[dir]
+		var bytes int64
+		for _, counts := range mix {
+			bytes += counts.Bytes
+		}
+
+		var parts []string
+		var rest int64
+		for i, lang := range sortedLanguages(mix) {
+			if i < maxMixLanguages {
+				parts = append(parts, fmt.Sprintf("%s %s", lang, share(mix[lang].Bytes, bytes)))
+			} else {
+				rest += mix[lang].Bytes
+			}
+		}
+		if len(mix) > maxMixLanguages {
+			parts = append(parts, fmt.Sprintf("other %s", share(rest, bytes)))
+		}
+
+		name := dir
+		if dir == "." {
+			name = "(root)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, formatFileSize(bytes), strings.Join(parts, ", "))
+	}
+	w.Flush()
+}
+
+// sortedLanguages returns the languages of counts by decreasing size
+func sortedLanguages(counts map[string]extract.Counts) []string {
+	langs := make([]string, 0, len(counts))
+	for lang := range counts {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		a, b := counts[langs[i]], counts[langs[j]]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return langs[i] < langs[j]
+	})
+	return langs
+}
+
+// share formats part as a percentage of total
+func share(part, total int64) string {
+	if total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+	"github.com/adil-chbada/extract-cli/pkg/extract"
+)
+
+// maxListedErrors is the number of unreadable paths listed in the summary
+const maxListedErrors = 10
+
+// categoryLabels names the categories in the summary
+var categoryLabels = map[extract.Category]string{
+	extract.CategoryCode:    "Code files",
+	extract.CategoryData:    "Data files",
+	extract.CategoryLocals:  "Local files",
+	extract.CategoryContext: "Context files",
+}
+
+// printSummary prints the generation summary to w. With a structured log format
+// the summary is logged as a single record instead, and quiet mode skips it.
+func printSummary(w io.Writer, summary *extract.Summary, dir string) {
+	if quiet {
+		return
+	}
+	if structuredLogs() {
+		logSummary(summary, dir)
+		return
+	}
+
+	result := summary.Result
+	fmt.Fprintf(w, "\n%s\n", successColor("✓ Generation completed successfully!"))
+	fmt.Fprintf(w, "Total files scanned: %d (%s, %s)\n", result.Total, formatFileSize(summary.TotalSize), formatLines(summary.TotalLines))
+	for _, category := range summary.Categories() {
+		if category.Category == extract.CategoryContext && category.Files == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "├─ %s: %d (%s, %d lines)\n", categoryLabels[category.Category], category.Files, formatFileSize(category.Size), category.Lines.Total)
+	}
+	fmt.Fprintf(w, "└─ Excluded files: %d\n", result.Excluded)
+
+	if len(summary.Workspaces) > 0 {
+		fmt.Fprintf(w, "\nWorkspaces: %d\n", len(summary.Workspaces))
+		for i, ws := range summary.Workspaces {
+			branch := "├─"
+			if i == len(summary.Workspaces)-1 {
+				branch = "└─"
+			}
+			fmt.Fprintf(w, "%s %s: %d files (%s)\n", branch, ws.Name, ws.Files, formatFileSize(ws.Size))
+		}
+	}
+
+	if oversized := summary.Oversized(); oversized > 0 {
+		handling := "were listed without their contents"
+		if summary.OversizedPolicy == config.OversizedTruncate {
+			handling = "were truncated to their first and last lines"
+		}
+		fmt.Fprintf(w, "\n%s\n", warnColor(fmt.Sprintf("⚠ %d files exceed max_file_size and %s", oversized, handling)))
+	}
+
+	if len(result.SkippedLinks) > 0 {
+		fmt.Fprintf(w, "\n%s\n", warnColor(fmt.Sprintf("⚠ %d symlinks were skipped (broken or outside the project):", len(result.SkippedLinks))))
+		for i, path := range result.SkippedLinks {
+			if i == maxListedErrors {
+				fmt.Fprintf(w, "  ... and %d more\n", len(result.SkippedLinks)-maxListedErrors)
+				break
+			}
+			fmt.Fprintf(w, "  - %s → %s\n", path, result.Links[path].Target)
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		fmt.Fprintf(w, "\n%s\n", warnColor(fmt.Sprintf("⚠ %d paths could not be read and were skipped:", len(result.Errors))))
+		for i, pathErr := range result.Errors {
+			if i == maxListedErrors {
+				fmt.Fprintf(w, "  ... and %d more\n", len(result.Errors)-maxListedErrors)
+				break
+			}
+			fmt.Fprintf(w, "  - %s: %v\n", pathErr.Path, pathErr.Err)
+		}
+	}
+	fmt.Fprintf(w, "\nMarkdown files written to: %s\n", dir)
+}
+
+// logSummary logs the generation summary as a structured record
+func logSummary(summary *extract.Summary, dir string) {
+	result := summary.Result
+	args := []any{
+		"output_dir", dir,
+		"scanned", result.Total,
+		"bytes", summary.TotalSize,
+		slog.Group("lines", "total", summary.TotalLines.Total, "code", summary.TotalLines.Code,
+			"comment", summary.TotalLines.Comment, "blank", summary.TotalLines.Blank),
+	}
+	for _, category := range summary.Categories() {
+		args = append(args, slog.Group(string(category.Category),
+			"files", category.Files, "bytes", category.Size, "lines", category.Lines.Total))
+	}
+	args = append(args,
+		"excluded", result.Excluded,
+		"oversized", summary.Oversized(),
+		"oversized_files", summary.OversizedPolicy,
+		"errors", len(result.Errors),
+		"skipped_links", len(result.SkippedLinks),
+		"workspaces", len(summary.Workspaces),
+	)
+	logSuccess("Generation completed", args...)
+
+	for _, path := range result.SkippedLinks {
+		logWarn("Symlink skipped", "path", path, "target", result.Links[path].Target)
+	}
+	for _, pathErr := range result.Errors {
+		logWarn("Path could not be read", "path", pathErr.Path, "error", pathErr.Err.Error())
+	}
+}
+
+// formatLines formats a line count with its code, comment and blank breakdown
+func formatLines(lines extract.LineCounts) string {
+	if !lines.Classified() {
+		return fmt.Sprintf("%d lines", lines.Total)
+	}
+	return fmt.Sprintf("%d lines: %d code, %d comment, %d blank", lines.Total, lines.Code, lines.Comment, lines.Blank)
+}
+
+// formatFileSize formats file size in human readable format
+func formatFileSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// writeStats writes run statistics as indented JSON to path, or to stdout for "-"
+func writeStats(stats *extract.Stats, path string) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode stats: %w", err)
+	}
+	data = append(data, '\n')
+
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+	logInfo(fmt.Sprintf("Wrote stats to %s", path))
+	return nil
+}
@@ -0,0 +1,460 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templatePathEnv lists extra template directories, separated like PATH
+const templatePathEnv = "EXTRACT_CLI_TEMPLATE_PATH"
+
+// templateExtensions are the file extensions of templates, in order of preference
+var templateExtensions = []string{".yaml", ".yml"}
+
+// templateSource is a location templates are loaded from
+type templateSource struct {
+	Name     string // Display name, e.g. "env", "user", "built-in" or a registered source name
+	Location string // Directory, git URL or "embedded"
+	fsys     fs.FS
+	dir      string // Directory inside fsys that holds the *.yaml and *.yml files
+}
+
+// templateInfo describes a single template and where it comes from
+type templateInfo struct {
+	Name     string
+	Source   string
+	Location string
+	Shadowed bool // A higher priority source provides a template with the same name
+}
+
+// registeredSource is a template source added with `init template add`
+type registeredSource struct {
+	Name     string `yaml:"name"`
+	Location string `yaml:"location"` // Git URL or local directory given by the user
+	Path     string `yaml:"path"`     // Local directory the templates are read from
+	Cloned   bool   `yaml:"cloned,omitempty"`
+}
+
+// sourcesFile is the on-disk list of registered template sources
+type sourcesFile struct {
+	Sources []registeredSource `yaml:"sources"`
+}
+
+// userConfigDir returns the extract-cli configuration directory (~/.config/extract-cli)
+func userConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "extract-cli"), nil
+}
+
+// userTemplatesDir returns the directory for user templates
+func userTemplatesDir() (string, error) {
+	dir, err := userConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "templates"), nil
+}
+
+// templateSources returns all template sources ordered from highest to lowest priority:
+// directories from EXTRACT_CLI_TEMPLATE_PATH, the user template directory, registered
+// sources and finally the built-in templates
+func templateSources() ([]templateSource, error) {
+	var sources []templateSource
+
+	for _, dir := range filepath.SplitList(os.Getenv(templatePathEnv)) {
+		if dir == "" {
+			continue
+		}
+		sources = append(sources, dirSource("env", dir, dir))
+	}
+
+	if dir, err := userTemplatesDir(); err == nil {
+		sources = append(sources, dirSource("user", dir, dir))
+	}
+
+	registered, err := loadRegisteredSources()
+	if err != nil {
+		return nil, err
+	}
+	for _, src := range registered.Sources {
+		sources = append(sources, dirSource(src.Name, src.Location, src.Path))
+	}
+
+	sources = append(sources, templateSource{
+		Name:     "built-in",
+		Location: "embedded",
+		fsys:     templatesFS,
+		dir:      "templates",
+	})
+
+	return sources, nil
+}
+
+// dirSource creates a source for a local directory. Repositories that keep their
+// templates in a templates/ subdirectory are supported as well.
+func dirSource(name, location, dir string) templateSource {
+	if info, err := os.Stat(filepath.Join(dir, "templates")); err == nil && info.IsDir() {
+		dir = filepath.Join(dir, "templates")
+	}
+	return templateSource{Name: name, Location: location, fsys: os.DirFS(dir), dir: "."}
+}
+
+// templates returns the names of all templates in a source
+func (s templateSource) templates() []string {
+	entries, err := fs.ReadDir(s.fsys, s.dir)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if name, ok := trimTemplateExt(entry.Name()); ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// read reads a template from the source, preferring the .yaml file when both
+// extensions exist
+func (s templateSource) read(name string) ([]byte, error) {
+	for _, ext := range templateExtensions {
+		data, err := fs.ReadFile(s.fsys, filepath.ToSlash(filepath.Join(s.dir, name+ext)))
+		if !errors.Is(err, fs.ErrNotExist) {
+			return data, err
+		}
+	}
+	return nil, fs.ErrNotExist
+}
+
+// trimTemplateExt removes the template extension of a file name
+func trimTemplateExt(file string) (string, bool) {
+	for _, ext := range templateExtensions {
+		if name, ok := strings.CutSuffix(file, ext); ok {
+			return name, true
+		}
+	}
+	return file, false
+}
+
+// validateTemplateName checks that a template or source name can be used as a
+// file name inside the template directories
+func validateTemplateName(kind, name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid %s name '%s'", kind, name)
+	}
+	return nil
+}
+
+// findTemplate loads a template from the first source that provides it
+func findTemplate(name string) ([]byte, templateInfo, error) {
+	if err := validateTemplateName("template", name); err != nil {
+		return nil, templateInfo{}, err
+	}
+
+	sources, err := templateSources()
+	if err != nil {
+		return nil, templateInfo{}, err
+	}
+
+	for _, src := range sources {
+		data, err := src.read(name)
+		if err == nil {
+			return data, templateInfo{Name: name, Source: src.Name, Location: src.Location}, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, templateInfo{}, fmt.Errorf("failed to read template '%s' from %s: %w", name, src.Name, err)
+		}
+	}
+
+	return nil, templateInfo{}, fmt.Errorf("template '%s' not found", name)
+}
+
+// allTemplates lists the templates of every source, marking the ones overridden by
+// a higher priority source
+func allTemplates() ([]templateInfo, error) {
+	sources, err := templateSources()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var infos []templateInfo
+	for _, src := range sources {
+		names := src.templates()
+		sort.Strings(names)
+		for _, name := range names {
+			infos = append(infos, templateInfo{
+				Name:     name,
+				Source:   src.Name,
+				Location: src.Location,
+				Shadowed: seen[name],
+			})
+			seen[name] = true
+		}
+	}
+
+	return infos, nil
+}
+
+// sourcesFilePath returns the path of the registered sources file
+func sourcesFilePath() (string, error) {
+	dir, err := userConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sources.yaml"), nil
+}
+
+// loadRegisteredSources reads the registered template sources
+func loadRegisteredSources() (*sourcesFile, error) {
+	path, err := sourcesFilePath()
+	if err != nil {
+		// Without a user config directory there is nothing registered
+		return &sourcesFile{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &sourcesFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template sources: %w", err)
+	}
+
+	var sources sourcesFile
+	if err := yaml.Unmarshal(data, &sources); err != nil {
+		return nil, fmt.Errorf("failed to parse template sources %s: %w", path, err)
+	}
+
+	return &sources, nil
+}
+
+// saveRegisteredSources writes the registered template sources
+func saveRegisteredSources(sources *sourcesFile) error {
+	path, err := sourcesFilePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(sources)
+	if err != nil {
+		return fmt.Errorf("failed to encode template sources: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write template sources: %w", err)
+	}
+
+	return nil
+}
+
+// isGitURL reports whether location looks like a remote git repository
+func isGitURL(location string) bool {
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://", "git@"} {
+		if strings.HasPrefix(location, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceNameFromLocation derives a source name from a git URL or path
+func sourceNameFromLocation(location string) string {
+	name := strings.TrimSuffix(strings.TrimRight(location, "/"), ".git")
+	if i := strings.LastIndexAny(name, "/:"); i >= 0 {
+		name = name[i+1:]
+	}
+	name, _ = trimTemplateExt(name)
+	return name
+}
+
+// addTemplateSource registers a template file, directory or git repository
+func addTemplateSource(location, name string) (string, error) {
+	if name == "" {
+		name = sourceNameFromLocation(location)
+	}
+	if err := validateTemplateName("source", name); err != nil {
+		return "", err
+	}
+
+	// A single template file is copied into the user template directory
+	if _, isFile := trimTemplateExt(location); isFile && !isGitURL(location) {
+		return name, addTemplateFile(location, name)
+	}
+
+	sources, err := loadRegisteredSources()
+	if err != nil {
+		return "", err
+	}
+	for _, src := range sources.Sources {
+		if src.Name == name {
+			return "", fmt.Errorf("template source '%s' already exists", name)
+		}
+	}
+
+	src := registeredSource{Name: name, Location: location}
+
+	if isGitURL(location) {
+		dir, err := userConfigDir()
+		if err != nil {
+			return "", err
+		}
+		src.Path = filepath.Join(dir, "sources", name)
+		src.Cloned = true
+
+		logInfo(fmt.Sprintf("Cloning %s into %s", location, src.Path))
+		if err := runGit("", "clone", "--depth", "1", location, src.Path); err != nil {
+			return "", fmt.Errorf("failed to clone %s: %w", location, err)
+		}
+	} else {
+		abs, err := filepath.Abs(location)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", location, err)
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("template source %s is not a directory", location)
+		}
+		src.Location = abs
+		src.Path = abs
+	}
+
+	sources.Sources = append(sources.Sources, src)
+	return name, saveRegisteredSources(sources)
+}
+
+// addTemplateFile copies a single template into the user template directory
+func addTemplateFile(path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+
+	var probe map[string]interface{}
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return fmt.Errorf("template %s is not valid YAML: %w", path, err)
+	}
+
+	dir, err := userTemplatesDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create template directory: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(dir, name+".yaml"), data, 0644)
+}
+
+// runGit runs git in dir, or in the current directory when dir is empty, with
+// its output on stderr
+func runGit(dir string, args ...string) error {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	git := exec.Command("git", args...)
+	git.Stdout = os.Stderr
+	git.Stderr = os.Stderr
+	return git.Run()
+}
+
+// updateTemplateSources fetches the latest commit of the cloned git sources, or of
+// the one named name. Local directories are read in place and need no update.
+// Returns the names of the updated sources.
+func updateTemplateSources(name string) ([]string, error) {
+	sources, err := loadRegisteredSources()
+	if err != nil {
+		return nil, err
+	}
+
+	var updated []string
+	for _, src := range sources.Sources {
+		if name != "" && src.Name != name {
+			continue
+		}
+		if !src.Cloned {
+			if name != "" {
+				return nil, fmt.Errorf("template source '%s' is a local directory and is always up to date", name)
+			}
+			continue
+		}
+
+		logInfo(fmt.Sprintf("Updating %s from %s", src.Name, src.Location))
+		if err := runGit(src.Path, "fetch", "--depth", "1", "origin"); err != nil {
+			return updated, fmt.Errorf("failed to fetch %s: %w", src.Location, err)
+		}
+		if err := runGit(src.Path, "reset", "--hard", "FETCH_HEAD"); err != nil {
+			return updated, fmt.Errorf("failed to update %s: %w", src.Path, err)
+		}
+		updated = append(updated, src.Name)
+	}
+
+	if name != "" && len(updated) == 0 {
+		return nil, fmt.Errorf("no template source named '%s'", name)
+	}
+	return updated, nil
+}
+
+// removeTemplateSource unregisters a source or deletes a user template
+func removeTemplateSource(name string) error {
+	if err := validateTemplateName("template source", name); err != nil {
+		return err
+	}
+
+	sources, err := loadRegisteredSources()
+	if err != nil {
+		return err
+	}
+
+	for i, src := range sources.Sources {
+		if src.Name != name {
+			continue
+		}
+		if src.Cloned {
+			if err := os.RemoveAll(src.Path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", src.Path, err)
+			}
+		}
+		sources.Sources = append(sources.Sources[:i], sources.Sources[i+1:]...)
+		return saveRegisteredSources(sources)
+	}
+
+	dir, err := userTemplatesDir()
+	if err != nil {
+		return err
+	}
+	removed := false
+	for _, ext := range templateExtensions {
+		path := filepath.Join(dir, name+ext)
+		if err := os.Remove(path); err == nil {
+			removed = true
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	if !removed {
+		return fmt.Errorf("no template source or user template named '%s'", name)
+	}
+
+	return nil
+}
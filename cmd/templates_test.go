@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+	"github.com/adil-chbada/extract-cli/internal/detect"
+	"github.com/adil-chbada/extract-cli/internal/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// TestTemplateGolden runs built-in templates against the fixture trees in
+// testdata/templates/<name> and compares the detected templates and the
+// categorized files with testdata/templates/<name>.golden
+func TestTemplateGolden(t *testing.T) {
+	names := []string{
+		"rust", "gradle", "maven", "dotnet", "swift", "android",
+		"nextjs", "django", "rails", "terraform", "elixir", "cmake",
+	}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join("testdata", "templates", name)
+			got := categorize(t, name, dir)
+
+			golden := dir + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run go test ./cmd -update): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("%s does not match:\n--- got\n%s\n--- want\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+// categorize scans dir with a built-in template and describes the result
+func categorize(t *testing.T, name, dir string) string {
+	t.Helper()
+
+	data, err := fs.ReadFile(templatesFS, "templates/"+name+".yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg config.Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	cfg.ProjectPath = dir
+	if err := cfg.ApplyDefaults(); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := detect.Detect(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := scanner.Scan(context.Background(), cfg.ProjectPath, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	b.WriteString("detected:\n")
+	for _, match := range matches {
+		fmt.Fprintf(&b, "  %s %s (%s)\n", match.Dir, match.Template, match.Reason)
+	}
+
+	listed := make(map[string]bool)
+	for _, category := range []struct {
+		name  string
+		files []string
+	}{
+		{"code", result.Code},
+		{"data", result.Data},
+		{"locals", result.Locals},
+	} {
+		fmt.Fprintf(&b, "%s:\n", category.name)
+		files := append([]string(nil), category.files...)
+		sort.Strings(files)
+		for _, file := range files {
+			fmt.Fprintf(&b, "  %s\n", file)
+			listed[file] = true
+		}
+	}
+
+	b.WriteString("excluded:\n")
+	for _, file := range fixtureFiles(t, dir) {
+		if !listed[file] {
+			fmt.Fprintf(&b, "  %s\n", file)
+		}
+	}
+	return b.String()
+}
+
+// fixtureFiles returns the files of a fixture tree relative to it, sorted
+func fixtureFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
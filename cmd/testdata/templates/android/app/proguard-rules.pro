@@ -0,0 +1 @@
+proguard-rules.pro
@@ -0,0 +1 @@
+MainActivity.kt
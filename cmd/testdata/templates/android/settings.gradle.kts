@@ -0,0 +1 @@
+settings.gradle.kts
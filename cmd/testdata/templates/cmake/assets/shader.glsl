@@ -0,0 +1 @@
+shader.glsl
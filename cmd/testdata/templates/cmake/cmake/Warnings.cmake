@@ -0,0 +1 @@
+Warnings.cmake
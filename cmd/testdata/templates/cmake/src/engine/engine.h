@@ -0,0 +1 @@
+engine.h
@@ -0,0 +1 @@
+test_engine.cpp
@@ -0,0 +1 @@
+json.hpp
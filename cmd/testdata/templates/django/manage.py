@@ -0,0 +1 @@
+manage.py
@@ -0,0 +1 @@
+asgi.py
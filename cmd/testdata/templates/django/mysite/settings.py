@@ -0,0 +1 @@
+settings.py
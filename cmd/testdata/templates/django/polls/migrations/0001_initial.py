@@ -0,0 +1 @@
+0001_initial.py
@@ -0,0 +1 @@
+models.py
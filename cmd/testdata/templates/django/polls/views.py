@@ -0,0 +1 @@
+views.py
@@ -0,0 +1 @@
+20240102_Seed.cs
@@ -0,0 +1 @@
+20240101_Init.cs
@@ -0,0 +1 @@
+HomeController.cs
@@ -0,0 +1 @@
+HomeControllerTests.cs
@@ -0,0 +1 @@
+.formatter.exs
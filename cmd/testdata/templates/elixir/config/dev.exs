@@ -0,0 +1 @@
+dev.exs
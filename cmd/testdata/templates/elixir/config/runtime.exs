@@ -0,0 +1 @@
+runtime.exs
@@ -0,0 +1 @@
+mix.exs
@@ -0,0 +1 @@
+application.ex
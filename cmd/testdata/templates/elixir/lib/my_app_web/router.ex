@@ -0,0 +1 @@
+router.ex
@@ -0,0 +1 @@
+20240101_create_users.exs
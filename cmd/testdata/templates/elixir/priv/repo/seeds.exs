@@ -0,0 +1 @@
+seeds.exs
@@ -0,0 +1 @@
+app.js
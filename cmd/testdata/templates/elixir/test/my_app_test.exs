@@ -0,0 +1 @@
+my_app_test.exs
@@ -0,0 +1 @@
+accounts_fixtures.ex
@@ -0,0 +1 @@
+build.gradle.kts
@@ -0,0 +1 @@
+Conventions.kt
@@ -0,0 +1 @@
+Util.java
@@ -0,0 +1 @@
+changelog.sql
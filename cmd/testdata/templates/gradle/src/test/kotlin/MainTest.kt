@@ -0,0 +1 @@
+MainTest.kt
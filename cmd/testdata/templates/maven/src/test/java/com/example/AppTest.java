@@ -0,0 +1 @@
+AppTest.java
@@ -0,0 +1 @@
+route.ts
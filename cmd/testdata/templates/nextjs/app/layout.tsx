@@ -0,0 +1 @@
+layout.tsx
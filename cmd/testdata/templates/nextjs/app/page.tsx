@@ -0,0 +1 @@
+page.tsx
@@ -0,0 +1 @@
+Button.tsx
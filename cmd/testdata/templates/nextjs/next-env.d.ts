@@ -0,0 +1 @@
+next-env.d.ts
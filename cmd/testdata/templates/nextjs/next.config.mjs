@@ -0,0 +1 @@
+next.config.mjs
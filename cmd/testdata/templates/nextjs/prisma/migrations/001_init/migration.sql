@@ -0,0 +1 @@
+migration.sql
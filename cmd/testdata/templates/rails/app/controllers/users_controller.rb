@@ -0,0 +1 @@
+users_controller.rb
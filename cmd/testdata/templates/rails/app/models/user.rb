@@ -0,0 +1 @@
+user.rb
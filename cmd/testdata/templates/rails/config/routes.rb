@@ -0,0 +1 @@
+routes.rb
@@ -0,0 +1 @@
+20240101000000_create_users.rb
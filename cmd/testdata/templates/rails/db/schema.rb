@@ -0,0 +1 @@
+schema.rb
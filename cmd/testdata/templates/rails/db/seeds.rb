@@ -0,0 +1 @@
+seeds.rb
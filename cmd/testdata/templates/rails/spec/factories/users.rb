@@ -0,0 +1 @@
+users.rb
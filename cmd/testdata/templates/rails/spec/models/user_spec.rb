@@ -0,0 +1 @@
+user_spec.rb
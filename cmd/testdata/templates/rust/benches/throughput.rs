@@ -0,0 +1 @@
+throughput.rs
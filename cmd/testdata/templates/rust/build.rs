@@ -0,0 +1 @@
+build.rs
@@ -0,0 +1 @@
+001_init.sql
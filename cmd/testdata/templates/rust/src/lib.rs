@@ -0,0 +1 @@
+lib.rs
@@ -0,0 +1 @@
+mod.rs
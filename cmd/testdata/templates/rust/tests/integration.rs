@@ -0,0 +1 @@
+integration.rs
@@ -0,0 +1 @@
+Alamofire.swift
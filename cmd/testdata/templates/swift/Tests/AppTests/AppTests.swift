@@ -0,0 +1 @@
+AppTests.swift
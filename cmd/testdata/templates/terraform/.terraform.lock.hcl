@@ -0,0 +1 @@
+.terraform.lock.hcl
@@ -0,0 +1 @@
+backend.tf
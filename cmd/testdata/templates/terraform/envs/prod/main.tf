@@ -0,0 +1 @@
+main.tf
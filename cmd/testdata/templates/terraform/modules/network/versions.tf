@@ -0,0 +1 @@
+versions.tf
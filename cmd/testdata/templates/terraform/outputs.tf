@@ -0,0 +1 @@
+outputs.tf
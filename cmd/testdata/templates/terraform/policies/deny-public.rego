@@ -0,0 +1 @@
+deny-public.rego
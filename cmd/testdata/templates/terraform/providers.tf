@@ -0,0 +1 @@
+providers.tf
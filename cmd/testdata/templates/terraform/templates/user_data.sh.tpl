@@ -0,0 +1 @@
+user_data.sh.tpl
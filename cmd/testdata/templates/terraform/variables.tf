@@ -0,0 +1 @@
+variables.tf
@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+	"github.com/adil-chbada/extract-cli/internal/detect"
+	"github.com/adil-chbada/extract-cli/pkg/extract"
+	"github.com/mattn/go-isatty"
+)
+
+// wizard drives the interactive init flow
+type wizard struct {
+	ctx context.Context
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// isInteractive reports whether both stdin and stdout are attached to a terminal
+func isInteractive() bool {
+	isTerminal := func(f *os.File) bool {
+		return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+	}
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// runWizard asks the user how to build the config and returns a description and the rendered YAML.
+// When stdin is not a terminal it falls back to the --detect defaults.
+func runWizard(ctx context.Context) (string, []byte, error) {
+	if !isInteractive() {
+		logWarn("Interactive mode requires a terminal; falling back to detected defaults")
+		return detectTemplateData()
+	}
+
+	w := &wizard{ctx: ctx, in: bufio.NewReader(os.Stdin), out: os.Stdout}
+	return w.run()
+}
+
+func (w *wizard) run() (string, []byte, error) {
+	fmt.Fprintf(w.out, "%s\n\n", infoColor("extract-cli config wizard"))
+
+	projectName, err := w.ask("Project name", projectNameFromDir("."))
+	if err != nil {
+		return "", nil, err
+	}
+
+	matches, err := w.chooseTemplates()
+	if err != nil {
+		return "", nil, err
+	}
+
+	cfg, err := buildDetectedConfig(matches)
+	if err != nil {
+		return "", nil, err
+	}
+	cfg.ProjectName = projectName
+
+	result, err := w.preview(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := w.toggleDirectories(cfg, result); err != nil {
+		return "", nil, err
+	}
+
+	if _, err := w.preview(cfg); err != nil {
+		return "", nil, err
+	}
+
+	ok, err := w.confirm("Write config?", true)
+	if err != nil {
+		return "", nil, err
+	}
+	if !ok {
+		return "", nil, fmt.Errorf("aborted by user")
+	}
+
+	summary := describeMatches(matches)
+	data, err := renderConfig(cfg, fmt.Sprintf("Generated by extract-cli init -i\nTemplates: %s", summary))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return summary, data, nil
+}
+
+// chooseTemplates offers the detected templates and lets the user pick a subset or
+// other templates. An invalid selection asks again.
+func (w *wizard) chooseTemplates() ([]detect.Match, error) {
+	detected, err := detectTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(w.out, "\nDetected templates:\n")
+	for i, match := range detected {
+		fmt.Fprintf(w.out, "  %d) %s in %s (%s)\n", i+1, infoColor(match.Template), match.Dir, match.Reason)
+	}
+
+	for {
+		answer, err := w.ask("Templates to use (numbers or template names, comma-separated)", "all")
+		if err != nil {
+			return nil, err
+		}
+		if answer == "all" {
+			return detected, nil
+		}
+
+		selected, err := selectTemplates(detected, answer)
+		if err != nil {
+			fmt.Fprintf(w.out, "%s\n", warnColor(err.Error()))
+			continue
+		}
+		if len(selected) == 0 {
+			return detected, nil
+		}
+		return selected, nil
+	}
+}
+
+// selectTemplates resolves an answer of numbers of detected templates and
+// template names
+func selectTemplates(detected []detect.Match, answer string) ([]detect.Match, error) {
+	var selected []detect.Match
+	for _, field := range splitList(answer) {
+		if n, err := strconv.Atoi(field); err == nil {
+			if n < 1 || n > len(detected) {
+				return nil, fmt.Errorf("invalid selection: %d", n)
+			}
+			selected = append(selected, detected[n-1])
+			continue
+		}
+
+		// Template names may carry a directory, e.g. "react:web"
+		name, dir, _ := strings.Cut(field, ":")
+		if dir == "" {
+			dir = "."
+		}
+		if _, err := loadTemplateConfig(name); err != nil {
+			return nil, err
+		}
+		selected = append(selected, detect.Match{Template: name, Dir: dir, Reason: "selected"})
+	}
+	return selected, nil
+}
+
+// preview scans the project with the given config and prints per-category counts
+func (w *wizard) preview(cfg *config.Config) (*extract.Result, error) {
+	scanCfg := cfg.Clone()
+	if err := scanCfg.ApplyDefaults(); err != nil {
+		return nil, err
+	}
+
+	extractor, err := extract.New(scanCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := extractor.Scan(w.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview scan: %w", err)
+	}
+
+	fmt.Fprintf(w.out, "\nPreview:\n")
+	fmt.Fprintf(w.out, "├─ Code files: %d\n", len(result.Code))
+	fmt.Fprintf(w.out, "├─ Data files: %d\n", len(result.Data))
+	fmt.Fprintf(w.out, "├─ Local files: %d\n", len(result.Locals))
+	fmt.Fprintf(w.out, "└─ Excluded files: %d\n", result.Excluded)
+
+	return result, nil
+}
+
+// toggleDirectories lists the top-level directories with their file counts and
+// lets the user toggle which of them are excluded until the answer is empty.
+// Directories excluded by the templates or the common exclusions are listed too,
+// so they can be included again.
+func (w *wizard) toggleDirectories(cfg *config.Config, result *extract.Result) error {
+	entries, err := os.ReadDir(cfg.ProjectPath)
+	if err != nil {
+		return fmt.Errorf("failed to list directories: %w", err)
+	}
+
+	counts := make(map[string]int)
+	for _, list := range [][]string{result.Code, result.Data, result.Locals, result.Context} {
+		for _, file := range list {
+			if dir, _, found := strings.Cut(file, "/"); found {
+				counts[dir]++
+			}
+		}
+	}
+
+	effective := cfg.Clone()
+	if err := effective.ApplyDefaults(); err != nil {
+		return err
+	}
+	var dirs []string
+	initial := make(map[string]string) // Pattern excluding each directory
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dirs = append(dirs, entry.Name())
+		if pattern, ok := effective.ExcludingPattern(entry.Name()); ok {
+			initial[entry.Name()] = pattern
+		}
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	excluded := make(map[string]bool)
+	for dir := range initial {
+		excluded[dir] = true
+	}
+	for {
+		fmt.Fprintf(w.out, "\nDirectories ([x] included, [ ] excluded):\n")
+		for i, dir := range dirs {
+			mark := "x"
+			if excluded[dir] {
+				mark = " "
+			}
+			note := fmt.Sprintf("%d files", counts[dir])
+			if pattern, ok := initial[dir]; ok {
+				note = "excluded by " + pattern
+			}
+			fmt.Fprintf(w.out, "  %d) [%s] %s/ (%s)\n", i+1, mark, dir, note)
+		}
+
+		answer, err := w.ask("Directories to toggle (numbers, comma-separated)", "done")
+		if err != nil {
+			return err
+		}
+		if answer == "done" {
+			break
+		}
+
+		toggled, err := selectDirectories(dirs, answer)
+		if err != nil {
+			fmt.Fprintf(w.out, "%s\n", warnColor(err.Error()))
+			continue
+		}
+		for _, dir := range toggled {
+			excluded[dir] = !excluded[dir]
+		}
+	}
+
+	for _, dir := range dirs {
+		_, wasExcluded := initial[dir]
+		switch {
+		case excluded[dir] && !wasExcluded:
+			cfg.ExcludePatterns = append(cfg.ExcludePatterns, dir+"/**")
+		case !excluded[dir] && wasExcluded:
+			includeDirectory(cfg, dir)
+		}
+	}
+	return nil
+}
+
+// selectDirectories resolves an answer of directory numbers
+func selectDirectories(dirs []string, answer string) ([]string, error) {
+	var selected []string
+	for _, field := range splitList(answer) {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > len(dirs) {
+			return nil, fmt.Errorf("invalid selection: %s", field)
+		}
+		selected = append(selected, dirs[n-1])
+	}
+	return selected, nil
+}
+
+// includeDirectory removes the exclude patterns written for a top-level directory
+// and, when it is still excluded, e.g. by the common exclusions, re-includes it
+// with a negated pattern
+func includeDirectory(cfg *config.Config, dir string) {
+	patterns := cfg.ExcludePatterns[:0]
+	for _, pattern := range cfg.ExcludePatterns {
+		if pattern != dir && pattern != dir+"/" && pattern != dir+"/**" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	cfg.ExcludePatterns = patterns
+
+	effective := cfg.Clone()
+	if err := effective.ApplyDefaults(); err == nil && effective.IsExcluded(dir) {
+		cfg.ExcludePatterns = append(cfg.ExcludePatterns, "!"+dir+"/**")
+	}
+}
+
+// ask prints a prompt with a default value and reads a single line answer
+func (w *wizard) ask(prompt, def string) (string, error) {
+	fmt.Fprintf(w.out, "%s [%s]: ", prompt, def)
+
+	line, err := w.readLine()
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+// confirm asks a yes/no question. An empty answer selects def; the end of the
+// input answers no.
+func (w *wizard) confirm(prompt string, def bool) (bool, error) {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	fmt.Fprintf(w.out, "%s [%s]: ", prompt, choices)
+
+	line, err := w.readLine()
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(line) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// readLine reads a line of input without surrounding spaces. It returns io.EOF
+// when the input ends before a line.
+func (w *wizard) readLine() (string, error) {
+	line, err := w.in.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", io.EOF
+	}
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// splitList splits a comma or space separated answer into its fields
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+	"github.com/adil-chbada/extract-cli/pkg/extract"
+)
+
+// newTestWizard returns a wizard reading the given answers
+func newTestWizard(answers string) *wizard {
+	return &wizard{ctx: context.Background(), in: bufio.NewReader(strings.NewReader(answers)), out: io.Discard}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		answer string
+		def    bool
+		want   bool
+	}{
+		{"\n", true, true},
+		{"\n", false, false},
+		{"", true, false}, // The end of the input answers no
+		{"y\n", false, true},
+		{"YES\n", false, true},
+		{"n\n", true, false},
+		{"nope\n", true, false},
+	}
+	for _, tt := range tests {
+		got, err := newTestWizard(tt.answer).confirm("Write config?", tt.def)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("confirm(%q, %v) = %v, want %v", tt.answer, tt.def, got, tt.want)
+		}
+	}
+}
+
+func TestToggleDirectories(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, "main.go", "api/a.go", "build/gen.go", "docs/b.go", "vendor/lib.go", "web/c.go")
+	result := &extract.Result{
+		Code: []string{"main.go", "api/a.go", "docs/b.go", "web/c.go"},
+	}
+
+	// Directories are listed in order: api, build, docs, vendor, web. Exclude api,
+	// docs and web, include docs again after an invalid answer, then include
+	// build and vendor, which the common exclusions and the template exclude.
+	cfg := &config.Config{ProjectPath: dir, ExcludePatterns: []string{"vendor/**", "*.log"}}
+	if err := newTestWizard("1,3 5\n6\n3\n2 4\n\n").toggleDirectories(cfg, result); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(cfg.ExcludePatterns, ",")
+	if want := "*.log,api/**,!build/**,web/**"; got != want {
+		t.Errorf("ExcludePatterns = %q, want %q", got, want)
+	}
+
+	if err := cfg.ApplyDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]bool{"api/a.go": true, "build/gen.go": false, "docs/b.go": false, "vendor/lib.go": false, "web/c.go": true} {
+		if got := cfg.IsExcluded(path); got != want {
+			t.Errorf("IsExcluded(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestToggleDirectoriesListsExcluded(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, "api/a.go", "dist/app.js")
+
+	var out strings.Builder
+	w := newTestWizard("")
+	w.out = &out
+	if err := w.toggleDirectories(&config.Config{ProjectPath: dir}, &extract.Result{Code: []string{"api/a.go"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"1) [x] api/ (1 files)", "2) [ ] dist/ (excluded by dist/**)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("listing is missing %q:\n%s", want, out.String())
+		}
+	}
+}
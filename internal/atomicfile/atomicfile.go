@@ -0,0 +1,181 @@
+package atomicfile
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// File is a temporary file that replaces its target path only when committed
+type File struct {
+	*os.File
+	path string
+	done bool
+}
+
+// Create creates a temporary file next to path. Nothing is written to path
+// until Commit is called. The file gets the permissions of a file created with
+// os.Create, i.e. 0666 minus the umask.
+func Create(path string) (*File, error) {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := createTemp(dir, "."+base+".", ".tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	return &File{File: tmp, path: path}, nil
+}
+
+// createTemp creates a new file in dir named prefix, a random number and suffix.
+// Unlike os.CreateTemp the file is created with mode 0666 before the umask.
+func createTemp(dir, prefix, suffix string) (*os.File, error) {
+	for try := 0; ; try++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 10)+suffix)
+		file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if errors.Is(err, os.ErrExist) && try < 10000 {
+			continue
+		}
+		return file, err
+	}
+}
+
+// Path returns the target path of the file
+func (f *File) Path() string {
+	return f.path
+}
+
+// Commit closes the temporary file and renames it to the target path
+func (f *File) Commit() error {
+	return CommitAll(f)
+}
+
+// CommitAll closes the temporary files and renames them to their target paths.
+// The targets are replaced one at a time; existing targets are moved aside first
+// and restored when a later rename fails, so a failed commit leaves the previous
+// files in place. A crash in the middle of a commit can still leave a mix of old
+// and new files, plus the moved-aside backups.
+func CommitAll(files ...*File) error {
+	var pending []*File
+	for _, f := range files {
+		if !f.done {
+			f.done = true
+			pending = append(pending, f)
+		}
+	}
+	removeTemps := func(files []*File) {
+		for _, f := range files {
+			os.Remove(f.File.Name())
+		}
+	}
+
+	for i, f := range pending {
+		if err := f.File.Close(); err != nil {
+			for _, f := range pending[i+1:] {
+				f.File.Close()
+			}
+			removeTemps(pending)
+			return fmt.Errorf("failed to close %s: %w", f.path, err)
+		}
+	}
+
+	// backups holds the moved-aside target of each replaced file, "" when the
+	// target did not exist
+	var backups []string
+	rollback := func() {
+		for i := len(backups) - 1; i >= 0; i-- {
+			if backups[i] == "" {
+				os.Remove(pending[i].path)
+			} else {
+				os.Rename(backups[i], pending[i].path)
+			}
+		}
+		removeTemps(pending[len(backups):])
+	}
+
+	for _, f := range pending {
+		backup, err := moveAside(f.path)
+		if err != nil {
+			rollback()
+			return fmt.Errorf("failed to replace %s: %w", f.path, err)
+		}
+		if err := os.Rename(f.File.Name(), f.path); err != nil {
+			if backup != "" {
+				os.Rename(backup, f.path)
+			}
+			rollback()
+			return fmt.Errorf("failed to replace %s: %w", f.path, err)
+		}
+		backups = append(backups, backup)
+	}
+
+	for _, backup := range backups {
+		if backup != "" {
+			os.Remove(backup)
+		}
+	}
+	return nil
+}
+
+// moveAside renames an existing regular file at path to a backup next to it and
+// returns the backup path, or "" when there is no file at path
+func moveAside(path string) (string, error) {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", path)
+	}
+
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	backup, err := createTemp(dir, "."+base+".", ".bak")
+	if err != nil {
+		return "", err
+	}
+	backup.Close()
+	if err := os.Rename(path, backup.Name()); err != nil {
+		os.Remove(backup.Name())
+		return "", err
+	}
+	return backup.Name(), nil
+}
+
+// WriteFile writes data to a temporary file and renames it to path, so readers
+// never see a partially written file
+func WriteFile(path string, data []byte) error {
+	file, err := Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Abort()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Commit()
+}
+
+// Abort closes and removes the temporary file. It is a no-op after Commit,
+// so it can be deferred unconditionally.
+func (f *File) Abort() {
+	if f.done {
+		return
+	}
+	f.done = true
+
+	f.File.Close()
+	os.Remove(f.File.Name())
+}
@@ -0,0 +1,107 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// create writes contents to a new temporary file for path
+func create(t *testing.T, path, contents string) *File {
+	t.Helper()
+	file, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// readFile returns the contents of path, or "<missing>"
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "<missing>"
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCommitAll(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")
+	if err := os.WriteFile(a, []byte("old a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CommitAll(create(t, a, "new a"), create(t, b, "new b")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, a); got != "new a" {
+		t.Errorf("a.md = %q, want %q", got, "new a")
+	}
+	if got := readFile(t, b); got != "new b" {
+		t.Errorf("b.md = %q, want %q", got, "new b")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("temporary or backup files were left behind: %v", entries)
+	}
+}
+
+func TestCommitAllRestoresOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	a, b, c := filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md"), filepath.Join(dir, "c.md")
+	if err := os.WriteFile(a, []byte("old a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A directory in place of c.md makes its rename fail
+	if err := os.Mkdir(c, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := CommitAll(create(t, a, "new a"), create(t, b, "new b"), create(t, c, "new c"))
+	if err == nil {
+		t.Fatal("CommitAll succeeded, want an error for the directory target")
+	}
+	if got := readFile(t, a); got != "old a" {
+		t.Errorf("a.md = %q, want the previous contents", got)
+	}
+	if got := readFile(t, b); got != "<missing>" {
+		t.Errorf("b.md = %q, want it removed again", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("temporary or backup files were left behind: %v", entries)
+	}
+}
+
+func TestCreateRespectsUmask(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.md")
+	if err := CommitAll(create(t, path, "a")); err != nil {
+		t.Fatal(err)
+	}
+
+	// os.Create uses the same mode before the umask
+	reference, err := os.Create(filepath.Join(dir, "reference"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference.Close()
+
+	got, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.Stat(reference.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Mode() != want.Mode() {
+		t.Errorf("mode = %v, want %v like os.Create", got.Mode(), want.Mode())
+	}
+}
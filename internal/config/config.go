@@ -1,6 +1,8 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,8 +19,183 @@ type Config struct {
 	DataPatterns    []string `yaml:"data_patterns"`
 	LocalPatterns   []string `yaml:"local_patterns"`
 	ExcludePatterns []string `yaml:"exclude_patterns"`
+	IncludePatterns []string `yaml:"include_patterns,omitempty"` // When set, only matching files are listed
 	MainLocalFiles  []string `yaml:"main_local_files"`
+	ContextFiles    []string `yaml:"context_files,omitempty"`  // Documentation rendered verbatim at the top of the code document
+	ContextGoAPI    bool     `yaml:"context_go_api,omitempty"` // Add an API listing of the Go packages to the context
 	UseRegex        bool     `yaml:"use_regex"`
+	Strict          bool     `yaml:"strict,omitempty"`        // Abort on the first unreadable path
+	ReportErrors    bool     `yaml:"report_errors,omitempty"` // Add a scan errors section to the code document
+	Symlinks        string   `yaml:"symlinks,omitempty"`      // skip, list (default) or follow
+
+	// File contents and size limits
+	IncludeContents        bool                `yaml:"include_contents,omitempty"`           // Embed file contents in fenced code blocks
+	MaxFileSize            ByteSize            `yaml:"max_file_size,omitempty"`              // Larger files are listed but their contents are not embedded
+	MaxFileSizePerCategory map[string]ByteSize `yaml:"max_file_size_per_category,omitempty"` // Overrides max_file_size for code, data or locals
+	OversizedFiles         string              `yaml:"oversized_files,omitempty"`            // skip (default) or truncate
+	TruncateLines          int                 `yaml:"truncate_lines,omitempty"`             // Lines kept at the start and end of truncated files
+	LargestFiles           int                 `yaml:"largest_files,omitempty"`              // Number of files in the "Largest Files" section
+	OutlinePatterns        []string            `yaml:"outline_patterns,omitempty"`           // Go files embedded as outlines, without function bodies
+	OutlineCategories      []string            `yaml:"outline_categories,omitempty"`         // Categories whose Go files are embedded as outlines
+	OutlineUnexported      bool                `yaml:"outline_unexported,omitempty"`         // Keep unexported declarations in outlines
+
+	// Output location and layout
+	OutputDir string `yaml:"output_dir,omitempty"` // Default for generate --output-dir
+	Format    string `yaml:"format,omitempty"`     // split (default) or consolidated
+	Template  string `yaml:"template,omitempty"`   // Built-in preset or path of a text/template file
+
+	// Instructions around the documents, with ${...} variables, inline or from a file
+	Preamble      string `yaml:"preamble,omitempty"`       // Text at the top of every document
+	PreambleFile  string `yaml:"preamble_file,omitempty"`  // File holding the preamble
+	Postamble     string `yaml:"postamble,omitempty"`      // Text at the bottom of every document
+	PostambleFile string `yaml:"postamble_file,omitempty"` // File holding the postamble
+
+	// Project tree diagram at the top of the documents
+	Tree         string `yaml:"tree,omitempty"`           // none (default), all or consolidated
+	TreeDepth    int    `yaml:"tree_depth,omitempty"`     // Directory levels shown, 0 for all
+	TreeMaxFiles int    `yaml:"tree_max_files,omitempty"` // Files listed per directory before the rest are collapsed
+	TreeSizes    bool   `yaml:"tree_sizes,omitempty"`     // Show the size of every file and directory
+
+	// Named variants of the config, selected with generate --profile
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+	Profile  string             `yaml:"-"` // Name of the applied profile
+
+	Origins map[string]Origin `yaml:"-"` // Where each key was set, see Origin
+
+	// Monorepo workspaces
+	Workspaces      []Workspace `yaml:"workspaces,omitempty"`
+	WorkspaceOutput string      `yaml:"workspace_output,omitempty"` // separate (default) or combined
+}
+
+// Oversized file handling
+const (
+	OversizedSkip     = "skip"     // List the file without its contents
+	OversizedTruncate = "truncate" // Embed the first and last TruncateLines lines
+)
+
+// defaultTruncateLines is used when truncate_lines is not set
+const defaultTruncateLines = 50
+
+// MaxFileSizeFor returns the size limit for a category, or 0 when unlimited
+func (c *Config) MaxFileSizeFor(category string) int64 {
+	if limit, ok := c.MaxFileSizePerCategory[category]; ok {
+		return int64(limit)
+	}
+	return int64(c.MaxFileSize)
+}
+
+// OversizedPolicy returns how oversized files are handled, defaulting to skip
+func (c *Config) OversizedPolicy() string {
+	if c.OversizedFiles == "" {
+		return OversizedSkip
+	}
+	return c.OversizedFiles
+}
+
+// TruncateLineCount returns the number of lines kept at each end of truncated files
+func (c *Config) TruncateLineCount() int {
+	if c.TruncateLines <= 0 {
+		return defaultTruncateLines
+	}
+	return c.TruncateLines
+}
+
+// Built-in output templates
+const (
+	TemplateDefault   = "default"    // The standard layout
+	TemplateMinimal   = "minimal"    // Headings and file paths only
+	TemplateDetailed  = "detailed"   // Tree and per-directory tables with languages and line counts
+	TemplateClaudeXML = "claude-xml" // <documents> XML as recommended for long context prompts
+	TemplateGitHub    = "github"     // Collapsible <details> sections for issues and pull requests
+)
+
+// TemplatePresets lists the built-in output templates
+var TemplatePresets = []string{TemplateDefault, TemplateMinimal, TemplateDetailed, TemplateClaudeXML, TemplateGitHub}
+
+// IsTemplatePreset reports whether name is a built-in output template
+func IsTemplatePreset(name string) bool {
+	for _, preset := range TemplatePresets {
+		if name == preset {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplateName returns the output template, defaulting to the default preset
+func (c *Config) TemplateName() string {
+	if c.Template == "" {
+		return TemplateDefault
+	}
+	return c.Template
+}
+
+// PreambleText returns the preamble, read from preamble_file when set
+func (c *Config) PreambleText() (string, error) {
+	return readText(c.Preamble, c.PreambleFile, "preamble")
+}
+
+// PostambleText returns the postamble, read from postamble_file when set
+func (c *Config) PostambleText() (string, error) {
+	return readText(c.Postamble, c.PostambleFile, "postamble")
+}
+
+// readText returns inline text, or the contents of file when it is set
+func readText(inline, file, key string) (string, error) {
+	if file == "" {
+		return inline, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_file: %w", key, err)
+	}
+	return string(data), nil
+}
+
+// Tree modes
+const (
+	TreeNone         = "none"         // No project tree
+	TreeAll          = "all"          // A tree of its files in every document
+	TreeConsolidated = "consolidated" // A tree in the consolidated project.md only
+)
+
+// defaultTreeMaxFiles is used when tree_max_files is not set
+const defaultTreeMaxFiles = 20
+
+// TreeMode returns where project trees are written, defaulting to none
+func (c *Config) TreeMode() string {
+	if c.Tree == "" {
+		return TreeNone
+	}
+	return c.Tree
+}
+
+// TreeMaxFileCount returns the number of files listed per directory of the tree,
+// or 0 when files are never collapsed
+func (c *Config) TreeMaxFileCount() int {
+	switch {
+	case c.TreeMaxFiles < 0:
+		return 0
+	case c.TreeMaxFiles == 0:
+		return defaultTreeMaxFiles
+	default:
+		return c.TreeMaxFiles
+	}
+}
+
+// Symlink policies
+const (
+	SymlinksSkip   = "skip"   // Ignore symbolic links
+	SymlinksList   = "list"   // List links as files without following them
+	SymlinksFollow = "follow" // Follow links that stay inside the project directory
+)
+
+// SymlinkPolicy returns the configured symlink policy, defaulting to list
+func (c *Config) SymlinkPolicy() string {
+	if c.Symlinks == "" {
+		return SymlinksList
+	}
+	return c.Symlinks
 }
 
 // getCommonExclusions returns common exclusion patterns that should be applied to all projects
@@ -88,35 +265,110 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	cfg, err := Parse(data, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.ApplyDefaults(); err != nil {
+		return nil, err
 	}
 
+	return cfg, nil
+}
+
+// ApplyDefaults merges the common exclusions, fills in default values and
+// resolves the project path to an absolute path
+func (c *Config) ApplyDefaults() error {
 	// Load and merge common exclusions
 	commonExclusions := getCommonExclusions()
 	// Merge common exclusions with project-specific ones
 	// Add common exclusions first, then project-specific ones
-	cfg.ExcludePatterns = append(commonExclusions, cfg.ExcludePatterns...)
+	c.ExcludePatterns = appendUnique(commonExclusions, c.ExcludePatterns...)
 
 	// Set defaults
-	if cfg.ProjectPath == "" {
-		cfg.ProjectPath = "."
+	if c.ProjectPath == "" {
+		c.ProjectPath = "."
 	}
-	if cfg.MainLocalFiles == nil {
-		cfg.MainLocalFiles = []string{"main.*", "index.*", "app.*"}
+	if c.MainLocalFiles == nil {
+		c.MainLocalFiles = []string{"main.*", "index.*", "app.*"}
 	}
 
 	// Convert relative path to absolute
-	if !filepath.IsAbs(cfg.ProjectPath) {
-		abs, err := filepath.Abs(cfg.ProjectPath)
+	if !filepath.IsAbs(c.ProjectPath) {
+		abs, err := filepath.Abs(c.ProjectPath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to resolve project path: %w", err)
+			return fmt.Errorf("failed to resolve project path: %w", err)
 		}
-		cfg.ProjectPath = abs
+		c.ProjectPath = abs
 	}
 
-	return &cfg, nil
+	return nil
+}
+
+// Clone returns a deep copy of the configuration
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.DataPatterns = append([]string(nil), c.DataPatterns...)
+	clone.LocalPatterns = append([]string(nil), c.LocalPatterns...)
+	clone.ExcludePatterns = append([]string(nil), c.ExcludePatterns...)
+	clone.IncludePatterns = append([]string(nil), c.IncludePatterns...)
+	clone.MainLocalFiles = append([]string(nil), c.MainLocalFiles...)
+	clone.ContextFiles = append([]string(nil), c.ContextFiles...)
+	clone.OutlinePatterns = append([]string(nil), c.OutlinePatterns...)
+	clone.OutlineCategories = append([]string(nil), c.OutlineCategories...)
+	if c.MaxFileSizePerCategory != nil {
+		clone.MaxFileSizePerCategory = make(map[string]ByteSize, len(c.MaxFileSizePerCategory))
+		for category, limit := range c.MaxFileSizePerCategory {
+			clone.MaxFileSizePerCategory[category] = limit
+		}
+	}
+	clone.Workspaces = append([]Workspace(nil), c.Workspaces...)
+	if c.Origins != nil {
+		clone.Origins = make(map[string]Origin, len(c.Origins))
+		for key, origin := range c.Origins {
+			clone.Origins[key] = origin
+		}
+	}
+	if c.Profiles != nil {
+		clone.Profiles = make(map[string]Profile, len(c.Profiles))
+		for name, profile := range c.Profiles {
+			clone.Profiles[name] = profile
+		}
+	}
+	return &clone
+}
+
+// Hash returns a SHA-256 fingerprint of the settings that affect the output. The
+// project path is left out so the same config hashes equally on every machine.
+func (c *Config) Hash() string {
+	clone := c.Clone()
+	clone.ProjectPath = ""
+	data, err := yaml.Marshal(clone)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// IsContextFile checks if a file matches context patterns
+func (c *Config) IsContextFile(path string) bool {
+	return c.matchesPatterns(path, c.ContextFiles)
+}
+
+// IsOutlined checks if the contents of a file of a category are embedded as a Go
+// outline: Go files matching outline_patterns or listed in outline_categories
+func (c *Config) IsOutlined(path, category string) bool {
+	if filepath.Ext(path) != ".go" {
+		return false
+	}
+	for _, outlined := range c.OutlineCategories {
+		if outlined == category {
+			return true
+		}
+	}
+	return c.matchesPatterns(path, c.OutlinePatterns)
 }
 
 // IsDataFile checks if a file matches data patterns
@@ -136,62 +388,66 @@ func (c *Config) IsMainLocalFile(path string) bool {
 
 // IsExcluded checks if a file should be excluded
 func (c *Config) IsExcluded(path string) bool {
-	return c.matchesPatterns(path, c.ExcludePatterns)
+	_, excluded := c.ExcludingPattern(path)
+	return excluded
 }
 
-// matchesPatterns checks if a path matches any of the given patterns
-func (c *Config) matchesPatterns(path string, patterns []string) bool {
-	for _, pattern := range patterns {
-		if c.matchesPattern(path, pattern) {
-			return true
+// ExcludingPattern returns the first exclude pattern that matches a file. A
+// pattern starting with "!" re-includes the files it matches, unless a later
+// pattern excludes them again.
+func (c *Config) ExcludingPattern(path string) (string, bool) {
+	excluding := ""
+	for _, pattern := range c.ExcludePatterns {
+		if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+			if excluding != "" && c.matchesPattern(path, negated) {
+				excluding = ""
+			}
+			continue
+		}
+		if excluding == "" && c.matchesPattern(path, pattern) {
+			excluding = pattern
 		}
 	}
-	return false
+	return excluding, excluding != ""
 }
 
-// matchesPattern checks if a path matches a single pattern
-func (c *Config) matchesPattern(path, pattern string) bool {
-	// Handle regex patterns (prefix with 're:')
-	if c.UseRegex && strings.HasPrefix(pattern, "re:") {
-		regexPattern := strings.TrimPrefix(pattern, "re:")
-		matched, err := regexp.MatchString(regexPattern, path)
-		if err != nil {
-			return false
-		}
-		return matched
-	}
+// IsIncluded checks if a file matches the include patterns. All files are
+// included when no include patterns are set.
+func (c *Config) IsIncluded(path string) bool {
+	return len(c.IncludePatterns) == 0 || c.matchesPatterns(path, c.IncludePatterns)
+}
 
-	// Handle glob patterns
-	matched, err := filepath.Match(pattern, filepath.Base(path))
-	if err != nil {
-		return false
-	}
-	if matched {
-		return true
+// validatePatterns checks the syntax of the glob and regex patterns
+func (c *Config) validatePatterns() error {
+	lists := []struct {
+		key      string
+		patterns []string
+	}{
+		{"include_patterns", c.IncludePatterns},
+		{"exclude_patterns", c.ExcludePatterns},
+		{"data_patterns", c.DataPatterns},
+		{"local_patterns", c.LocalPatterns},
+		{"main_local_files", c.MainLocalFiles},
+		{"context_files", c.ContextFiles},
+		{"outline_patterns", c.OutlinePatterns},
 	}
-
-	// Handle directory patterns (e.g., "data/**")
-	if strings.Contains(pattern, "**") {
-		// Convert glob pattern to regex-like matching
-		parts := strings.Split(pattern, "**")
-		if len(parts) == 2 {
-			prefix := strings.TrimSuffix(parts[0], "/")
-			suffix := strings.TrimPrefix(parts[1], "/")
-
-			// Check if path starts with prefix and ends with suffix
-			if prefix != "" && !strings.HasPrefix(path, prefix) {
-				return false
+	for _, list := range lists {
+		for _, pattern := range list.patterns {
+			if list.key == "exclude_patterns" {
+				pattern = strings.TrimPrefix(pattern, "!")
 			}
-			if suffix != "" && !strings.HasSuffix(path, suffix) {
-				return false
+			if c.UseRegex && strings.HasPrefix(pattern, "re:") {
+				if _, err := regexp.Compile(strings.TrimPrefix(pattern, "re:")); err != nil {
+					return fmt.Errorf("invalid %s pattern %q: %w", list.key, pattern, err)
+				}
+				continue
+			}
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid %s pattern %q: %w", list.key, pattern, err)
 			}
-			return true
 		}
 	}
-
-	// Check if the pattern matches the full path
-	matched, err = filepath.Match(pattern, path)
-	return err == nil && matched
+	return nil
 }
 
 // Validate validates the configuration
@@ -204,5 +460,85 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("project_path does not exist: %s", c.ProjectPath)
 	}
 
+	switch c.SymlinkPolicy() {
+	case SymlinksSkip, SymlinksList, SymlinksFollow:
+	default:
+		return fmt.Errorf("invalid symlinks value %q (expected skip, list or follow)", c.Symlinks)
+	}
+
+	switch c.OversizedPolicy() {
+	case OversizedSkip, OversizedTruncate:
+	default:
+		return fmt.Errorf("invalid oversized_files value %q (expected skip or truncate)", c.OversizedFiles)
+	}
+
+	switch c.OutputFormat() {
+	case FormatSplit, FormatConsolidated:
+	default:
+		return fmt.Errorf("invalid format value %q (expected split or consolidated)", c.Format)
+	}
+
+	if name := c.TemplateName(); !IsTemplatePreset(name) {
+		if _, err := os.Stat(name); err != nil {
+			return fmt.Errorf("template %q is neither a preset (%s) nor a readable file", name, strings.Join(TemplatePresets, ", "))
+		}
+	}
+
+	for _, text := range []struct{ key, inline, file string }{
+		{"preamble", c.Preamble, c.PreambleFile},
+		{"postamble", c.Postamble, c.PostambleFile},
+	} {
+		if text.inline != "" && text.file != "" {
+			return fmt.Errorf("%s and %s_file cannot both be set", text.key, text.key)
+		}
+		if text.file != "" {
+			if _, err := os.Stat(text.file); err != nil {
+				return fmt.Errorf("%s_file not found: %s", text.key, text.file)
+			}
+		}
+	}
+
+	switch c.TreeMode() {
+	case TreeNone, TreeAll, TreeConsolidated:
+	default:
+		return fmt.Errorf("invalid tree value %q (expected none, all or consolidated)", c.Tree)
+	}
+
+	if c.TreeDepth < 0 {
+		return fmt.Errorf("invalid tree_depth %d (expected 0 or more)", c.TreeDepth)
+	}
+
+	switch c.WorkspaceMode() {
+	case WorkspacesSeparate, WorkspacesCombined:
+	default:
+		return fmt.Errorf("invalid workspace_output value %q (expected separate or combined)", c.WorkspaceOutput)
+	}
+
+	for _, ws := range c.Workspaces {
+		if err := ws.validateName(); err != nil {
+			return err
+		}
+	}
+
+	for category := range c.MaxFileSizePerCategory {
+		switch category {
+		case "code", "data", "locals":
+		default:
+			return fmt.Errorf("invalid max_file_size_per_category key %q (expected code, data or locals)", category)
+		}
+	}
+
+	if err := c.validatePatterns(); err != nil {
+		return err
+	}
+
+	for _, category := range c.OutlineCategories {
+		switch category {
+		case "code", "data", "locals":
+		default:
+			return fmt.Errorf("invalid outline_categories value %q (expected code, data or locals)", category)
+		}
+	}
+
 	return nil
-}
\ No newline at end of file
+}
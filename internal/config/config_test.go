@@ -0,0 +1,50 @@
+package config
+
+import "testing"
+
+func TestValidateWorkspaceNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"", true},
+		{"api", true},
+		{"svc.api", true},
+		{"..", false},
+		{".", false},
+		{"../outside", false},
+		{"a/b", false},
+		{`a\b`, false},
+	}
+	for _, tt := range tests {
+		cfg := &Config{ProjectPath: ".", Workspaces: []Workspace{{Path: "services/api", Name: tt.name}}}
+		if err := cfg.ApplyDefaults(); err != nil {
+			t.Fatal(err)
+		}
+		err := cfg.Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("Validate() with workspace name %q = %v, want valid = %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestExcludeNegation(t *testing.T) {
+	cfg := &Config{ExcludePatterns: []string{"build/**", "*.log", "!build/**", "build/tmp/**"}}
+	tests := []struct {
+		path    string
+		pattern string
+	}{
+		{"build", ""},
+		{"build/gen.go", ""},
+		{"build/tmp/x.go", "build/tmp/**"},
+		{"dist/debug.log", "*.log"},
+	}
+	for _, tt := range tests {
+		if got, _ := cfg.ExcludingPattern(tt.path); got != tt.pattern {
+			t.Errorf("ExcludingPattern(%q) = %q, want %q", tt.path, got, tt.pattern)
+		}
+	}
+	if err := cfg.validatePatterns(); err != nil {
+		t.Errorf("negated pattern was rejected: %v", err)
+	}
+}
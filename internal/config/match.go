@@ -0,0 +1,86 @@
+package config
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Patterns are globs matched against paths relative to the project, with "/" as
+// separator. A pattern without a slash matches the base name in any directory;
+// "**" matches zero or more whole path segments, see matchSegments.
+
+// matchesPatterns checks if a path matches any of the given patterns
+func (c *Config) matchesPatterns(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if c.matchesPattern(path, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPattern checks if a path matches a single pattern
+func (c *Config) matchesPattern(path, pattern string) bool {
+	// Handle regex patterns (prefix with 're:')
+	if c.UseRegex && strings.HasPrefix(pattern, "re:") {
+		regexPattern := strings.TrimPrefix(pattern, "re:")
+		matched, err := regexp.MatchString(regexPattern, path)
+		if err != nil {
+			return false
+		}
+		return matched
+	}
+
+	// Handle glob patterns
+	matched, err := filepath.Match(pattern, filepath.Base(path))
+	if err != nil {
+		return false
+	}
+	if matched {
+		return true
+	}
+
+	// Handle directory patterns (e.g., "data/**", "**/*.csv", "*/build/**")
+	if strings.Contains(pattern, "**") {
+		return matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+	}
+
+	// Check if the pattern matches the full path
+	matched, err = filepath.Match(pattern, path)
+	return err == nil && matched
+}
+
+// matchSegments matches path segments against glob segments where "**" matches
+// zero or more whole segments
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive "**" segments
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern, path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(path) == 0 {
+			return false
+		}
+		// "**" inside a segment (e.g. "foo**") behaves like "*"
+		segment := strings.ReplaceAll(pattern[0], "**", "*")
+		if matched, err := filepath.Match(segment, path[0]); err != nil || !matched {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+
+	return len(path) == 0
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchesPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		// Patterns without a slash match the base name anywhere
+		{"*.csv", "report.csv", true},
+		{"*.csv", "data/2024/report.csv", true},
+		{"Makefile", "tools/Makefile", true},
+
+		// Patterns with a slash match the whole path
+		{"docs/*.md", "docs/guide.md", true},
+		{"docs/*.md", "docs/api/guide.md", false},
+
+		// "**" at the end matches everything below a directory
+		{"data/**", "data/x.json", true},
+		{"data/**", "data/a/b/c.json", true},
+		{"data/**", "database/x.json", false},
+		{"data/**", "data", true},
+		{"build/**", "build.rs", false},
+		{"build/**", "buildSrc/Main.kt", false},
+
+		// "**" at the start matches at any depth, including the root
+		{"**/*.csv", "x.csv", true},
+		{"**/*.csv", "a/b/x.csv", true},
+		{"**/*.csv", "a/b/x.csv.bak", false},
+		{"**/locales/**", "web/src/locales/en.json", true},
+		{"**/locales/**", "web/src/locale/en.json", false},
+
+		// "**" in the middle matches zero or more directories
+		{"data/**/*.json", "data/x.json", true},
+		{"data/**/*.json", "data/a/b/x.json", true},
+		{"data/**/*.json", "data/a/b/x.yaml", false},
+		{"data/**/*.json", "database/x.json", false},
+		{"src/**/test/**", "src/a/test/b.go", true},
+
+		// Single stars stay within one segment
+		{"*/build/**", "app/build/out.jar", true},
+		{"*/build/**", "build/out.jar", false},
+		{"*/build/**", "libs/core/build/out.jar", false},
+		{"app/src/main/res/values-*/**", "app/src/main/res/values-fr/strings.xml", true},
+
+		// "**" inside a segment behaves like "*"
+		{"cmake-build-**/**", "cmake-build-debug/CMakeCache.txt", true},
+	}
+
+	cfg := &Config{}
+	for _, tt := range tests {
+		if got := cfg.matchesPattern(tt.path, tt.pattern); got != tt.want {
+			t.Errorf("matchesPattern(%q, %q) = %v, want %v", tt.path, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesRegexPattern(t *testing.T) {
+	cfg := &Config{UseRegex: true}
+	if !cfg.matchesPattern("internal/gen/api.pb.go", `re:\.pb\.go$`) {
+		t.Error("regex pattern did not match")
+	}
+	if cfg.matchesPattern("internal/api.go", `re:\.pb\.go$`) {
+		t.Error("regex pattern matched an unrelated path")
+	}
+
+	cfg.UseRegex = false
+	if cfg.matchesPattern("internal/gen/api.pb.go", `re:\.pb\.go$`) {
+		t.Error("regex pattern matched with use_regex disabled")
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"**", "a/b/c", true},
+		{"a/**/**/c", "a/c", true},
+		{"a/**/**/c", "a/b/b/c", true},
+		{"a/**/c", "a/b/c/d", false},
+		{"a/*", "a/b/c", false},
+		{"a/[", "a/b", false}, // Malformed globs never match
+	}
+	for _, tt := range tests {
+		if got := matchSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.path, "/")); got != tt.want {
+			t.Errorf("matchSegments(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Merge adds the patterns of other into c, prefixing them with dir so they only
+// apply below that directory. Duplicate patterns are skipped.
+func (c *Config) Merge(other *Config, dir string) {
+	c.DataPatterns = appendUnique(c.DataPatterns, prefixPatterns(other.DataPatterns, dir, false)...)
+	c.LocalPatterns = appendUnique(c.LocalPatterns, prefixPatterns(other.LocalPatterns, dir, false)...)
+	c.MainLocalFiles = appendUnique(c.MainLocalFiles, prefixPatterns(other.MainLocalFiles, dir, false)...)
+	c.ContextFiles = appendUnique(c.ContextFiles, prefixPatterns(other.ContextFiles, dir, false)...)
+	// Basename-only exclusions (*.log, .DS_Store) stay global
+	c.ExcludePatterns = appendUnique(c.ExcludePatterns, prefixPatterns(other.ExcludePatterns, dir, true)...)
+	c.UseRegex = c.UseRegex || other.UseRegex
+}
+
+// PrefixPattern rewrites a pattern so it only matches below dir. A pattern without
+// a slash matches the base name at any depth, so it becomes dir/**/pattern. The "!"
+// of negated exclude patterns is kept in front.
+func PrefixPattern(pattern, dir string) string {
+	dir = strings.Trim(path.Clean(dir), "/")
+	if dir == "." || dir == "" {
+		return pattern
+	}
+	if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+		return "!" + PrefixPattern(negated, dir)
+	}
+
+	if strings.HasPrefix(pattern, "re:") {
+		// Only anchored regexes can be safely re-rooted
+		if strings.HasPrefix(pattern, "re:^") {
+			return "re:^" + regexp.QuoteMeta(dir+"/") + strings.TrimPrefix(pattern, "re:^")
+		}
+		return pattern
+	}
+
+	if !strings.Contains(pattern, "/") {
+		return dir + "/**/" + pattern
+	}
+	return dir + "/" + strings.TrimPrefix(pattern, "/")
+}
+
+// prefixPatterns applies PrefixPattern to every pattern in the list
+func prefixPatterns(patterns []string, dir string, keepBasenames bool) []string {
+	prefixed := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if name := strings.TrimPrefix(pattern, "!"); keepBasenames && !strings.Contains(name, "/") && !strings.HasPrefix(name, "re:") {
+			prefixed = append(prefixed, pattern)
+			continue
+		}
+		prefixed = append(prefixed, PrefixPattern(pattern, dir))
+	}
+	return prefixed
+}
+
+// appendUnique appends the values that are not already present in list
+func appendUnique(list []string, values ...string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, v := range list {
+		seen[v] = true
+	}
+	for _, v := range values {
+		if !seen[v] {
+			list = append(list, v)
+			seen[v] = true
+		}
+	}
+	return list
+}
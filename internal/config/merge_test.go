@@ -0,0 +1,41 @@
+package config
+
+import "testing"
+
+func TestMergeKeepsPatternsBelowDir(t *testing.T) {
+	cfg := &Config{}
+	cfg.Merge(&Config{
+		DataPatterns:    []string{"*.config.js", "public/**"},
+		ExcludePatterns: []string{"*.log", "dist/**"},
+	}, "web")
+
+	tests := []struct {
+		path     string
+		data     bool
+		excluded bool
+	}{
+		// Base name patterns keep matching at any depth below the directory
+		{"web/x.config.js", true, false},
+		{"web/src/app/x.config.js", true, false},
+		{"x.config.js", false, false},
+		{"api/src/x.config.js", false, false},
+		{"web/public/logo.svg", true, false},
+		{"public/logo.svg", false, false},
+
+		// Base name exclusions stay global
+		{"api/debug.log", false, true},
+		{"web/dist/app.js", false, true},
+		{"dist/app.js", false, false},
+	}
+	for _, tt := range tests {
+		if got := cfg.IsDataFile(tt.path); got != tt.data {
+			t.Errorf("IsDataFile(%q) = %v, want %v", tt.path, got, tt.data)
+		}
+		if got := cfg.IsExcluded(tt.path); got != tt.excluded {
+			t.Errorf("IsExcluded(%q) = %v, want %v", tt.path, got, tt.excluded)
+		}
+	}
+	if want := "web/**/*.config.js"; cfg.DataPatterns[0] != want {
+		t.Errorf("DataPatterns[0] = %q, want %q", cfg.DataPatterns[0], want)
+	}
+}
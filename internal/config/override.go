@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of the environment variables that override config keys,
+// e.g. EXTRACT_PROJECT_PATH for project_path
+const EnvPrefix = "EXTRACT_"
+
+// Origin sources, from lowest to highest precedence
+const (
+	OriginDefault = "default" // Built-in default
+	OriginFile    = "file"    // Config file
+	OriginProfile = "profile" // Profile selected with --profile
+	OriginEnv     = "env"     // EXTRACT_* environment variable
+	OriginFlag    = "flag"    // Command line flag
+)
+
+// Origin describes where the value of a config key came from
+type Origin struct {
+	Source string // One of the Origin* constants
+	Name   string // File path, profile, environment variable or flag
+}
+
+func (o Origin) String() string {
+	if o.Name == "" {
+		return o.Source
+	}
+	return o.Source + " " + o.Name
+}
+
+// Parse decodes a YAML config without applying defaults. Every key present in the
+// data is recorded with path as its origin.
+func Parse(data []byte, path string) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	var cfg Config
+	if len(doc.Content) == 0 {
+		return &cfg, nil
+	}
+	if err := doc.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	root := doc.Content[0]
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			cfg.SetOrigin(root.Content[i].Value, Origin{Source: OriginFile, Name: path})
+		}
+	}
+
+	return &cfg, nil
+}
+
+// Keys returns the config keys that can be overridden, sorted
+func Keys() []string {
+	var keys []string
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if key := yamlKey(t.Field(i)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EnvName returns the environment variable that overrides a config key
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+// Origin returns where the value of a config key came from
+func (c *Config) Origin(key string) Origin {
+	if origin, ok := c.Origins[key]; ok {
+		return origin
+	}
+	return Origin{Source: OriginDefault}
+}
+
+// SetOrigin records where the value of a config key came from
+func (c *Config) SetOrigin(key string, origin Origin) {
+	if c.Origins == nil {
+		c.Origins = make(map[string]Origin)
+	}
+	c.Origins[key] = origin
+}
+
+// Set overrides a config key with a value given as text. Lists are written as
+// comma separated values or as a YAML flow sequence, maps and structured keys
+// such as workspaces as YAML.
+func (c *Config) Set(key, value string, origin Origin) error {
+	field, ok := c.field(key)
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+
+	if err := setValue(field, value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	c.SetOrigin(key, origin)
+	return nil
+}
+
+// ApplyEnv overrides config keys with the EXTRACT_* variables found in environ.
+// Variables that do not name a config key are ignored.
+func (c *Config) ApplyEnv(environ []string) error {
+	keys := make(map[string]string)
+	for _, key := range Keys() {
+		keys[EnvName(key)] = key
+	}
+
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		key, ok := keys[name]
+		if !ok {
+			continue
+		}
+		if err := c.Set(key, value, Origin{Source: OriginEnv, Name: name}); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// ParseAssignment splits a key=value override
+func ParseAssignment(assignment string) (string, string, error) {
+	key, value, ok := strings.Cut(assignment, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid override %q (expected key=value)", assignment)
+	}
+	return key, value, nil
+}
+
+// field returns the struct field of a config key
+func (c *Config) field(key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if yamlKey(t.Field(i)) == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// yamlKey returns the YAML key of a struct field, or "" for fields not read from YAML
+func yamlKey(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// setValue decodes a textual value into a config field
+func setValue(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == reflect.TypeOf(ByteSize(0)):
+		size, err := ParseByteSize(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(size))
+		return nil
+
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+		return nil
+
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		field.SetBool(b)
+		return nil
+
+	case field.Kind() == reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetInt(int64(n))
+		return nil
+
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String &&
+		!strings.HasPrefix(strings.TrimSpace(value), "["):
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+		return nil
+
+	case field.Kind() == reflect.Slice || field.Kind() == reflect.Map:
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(value), &node); err != nil {
+			return err
+		}
+		fresh := reflect.New(field.Type())
+		if len(node.Content) > 0 {
+			if err := node.Content[0].Decode(fresh.Interface()); err != nil {
+				return err
+			}
+		}
+		field.Set(fresh.Elem())
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatSplit        = "split"        // One document per category
+	FormatConsolidated = "consolidated" // A single project.md with a section per category
+)
+
+// PatternSet holds the pattern lists of a config
+type PatternSet struct {
+	DataPatterns    []string `yaml:"data_patterns,omitempty"`
+	LocalPatterns   []string `yaml:"local_patterns,omitempty"`
+	MainLocalFiles  []string `yaml:"main_local_files,omitempty"`
+	ContextFiles    []string `yaml:"context_files,omitempty"`
+	ExcludePatterns []string `yaml:"exclude_patterns,omitempty"`
+	IncludePatterns []string `yaml:"include_patterns,omitempty"`
+}
+
+// Profile is a named variant of a config, selected with generate --profile
+type Profile struct {
+	Description string `yaml:"description,omitempty"`
+
+	// Patterns set directly on the profile replace the root patterns; the common
+	// exclusions always stay. Patterns under extend are added to the root patterns.
+	PatternSet `yaml:",inline"`
+	Extend     PatternSet `yaml:"extend,omitempty"`
+
+	OutputDir string `yaml:"output_dir,omitempty"`
+	Format    string `yaml:"format,omitempty"` // split or consolidated
+}
+
+// OutputFormat returns the output format, defaulting to split
+func (c *Config) OutputFormat() string {
+	if c.Format == "" {
+		return FormatSplit
+	}
+	return c.Format
+}
+
+// ProfileNames returns the names of the profiles defined in the config, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile applies the overrides of a named profile to the config
+func (c *Config) ApplyProfile(name string) error {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return fmt.Errorf("unknown profile %q: the config does not define any profiles", name)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	origin := Origin{Source: OriginProfile, Name: name}
+	replace := func(key string, list *[]string, patterns []string) {
+		if patterns != nil {
+			*list = append([]string(nil), patterns...)
+			c.SetOrigin(key, origin)
+		}
+	}
+	extend := func(key string, list *[]string, patterns []string) {
+		if len(patterns) > 0 {
+			*list = appendUnique(*list, patterns...)
+			c.SetOrigin(key, origin)
+		}
+	}
+
+	replace("data_patterns", &c.DataPatterns, profile.DataPatterns)
+	replace("local_patterns", &c.LocalPatterns, profile.LocalPatterns)
+	replace("main_local_files", &c.MainLocalFiles, profile.MainLocalFiles)
+	replace("context_files", &c.ContextFiles, profile.ContextFiles)
+	replace("include_patterns", &c.IncludePatterns, profile.IncludePatterns)
+	if profile.ExcludePatterns != nil {
+		replace("exclude_patterns", &c.ExcludePatterns, append(getCommonExclusions(), profile.ExcludePatterns...))
+	}
+
+	extend("data_patterns", &c.DataPatterns, profile.Extend.DataPatterns)
+	extend("local_patterns", &c.LocalPatterns, profile.Extend.LocalPatterns)
+	extend("main_local_files", &c.MainLocalFiles, profile.Extend.MainLocalFiles)
+	extend("context_files", &c.ContextFiles, profile.Extend.ContextFiles)
+	extend("exclude_patterns", &c.ExcludePatterns, profile.Extend.ExcludePatterns)
+	extend("include_patterns", &c.IncludePatterns, profile.Extend.IncludePatterns)
+
+	if profile.OutputDir != "" {
+		c.OutputDir = profile.OutputDir
+		c.SetOrigin("output_dir", origin)
+	}
+	if profile.Format != "" {
+		c.Format = profile.Format
+		c.SetOrigin("format", origin)
+	}
+	c.Profile = name
+
+	return nil
+}
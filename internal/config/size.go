@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ByteSize is a file size that can be written as a number of bytes or with a
+// unit suffix in the config, e.g. 512KB, 1.5MB or 2GB
+type ByteSize int64
+
+// sizeUnits maps unit suffixes to their multiplier, longest suffixes first
+var sizeUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+	{"B", 1},
+}
+
+// ParseByteSize parses a size such as "100", "512KB" or "1.5MB"
+func ParseByteSize(s string) (ByteSize, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	if value == "" {
+		return 0, nil
+	}
+
+	factor := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			factor = unit.factor
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return ByteSize(n * float64(factor)), nil
+}
+
+// UnmarshalYAML accepts plain numbers and sizes with a unit suffix
+func (b *ByteSize) UnmarshalYAML(node *yaml.Node) error {
+	size, err := ParseByteSize(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*b = size
+	return nil
+}
+
+// String formats the size with the largest unit that divides it evenly
+func (b ByteSize) String() string {
+	switch {
+	case b > 0 && b%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", b>>30)
+	case b > 0 && b%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", b>>20)
+	case b > 0 && b%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", b>>10)
+	default:
+		return strconv.FormatInt(int64(b), 10)
+	}
+}
+
+// MarshalYAML writes the size in its human readable form
+func (b ByteSize) MarshalYAML() (interface{}, error) {
+	return b.String(), nil
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Workspace output modes
+const (
+	WorkspacesSeparate = "separate" // One set of documents per workspace, in a subdirectory named after it
+	WorkspacesCombined = "combined" // One set of documents with a section per workspace
+)
+
+// Workspace is a project root inside a monorepo. Paths are relative to project_path
+// and may contain glob characters, e.g. "services/*".
+type Workspace struct {
+	Path string `yaml:"path"`
+	// Name of the workspace; for glob paths it is used as a prefix of the matched directory names
+	Name string `yaml:"name,omitempty"`
+
+	// Pattern overrides, relative to the workspace directory. Data, local, main local,
+	// context and include patterns replace the root patterns; exclusions are added to them.
+	DataPatterns    []string `yaml:"data_patterns,omitempty"`
+	LocalPatterns   []string `yaml:"local_patterns,omitempty"`
+	MainLocalFiles  []string `yaml:"main_local_files,omitempty"`
+	ContextFiles    []string `yaml:"context_files,omitempty"`
+	ExcludePatterns []string `yaml:"exclude_patterns,omitempty"`
+	IncludePatterns []string `yaml:"include_patterns,omitempty"`
+}
+
+// ResolvedWorkspace is a workspace directory matched by a Workspace entry
+type ResolvedWorkspace struct {
+	Name   string
+	Dir    string  // Directory relative to the project path, with forward slashes
+	Config *Config // Config of the workspace; patterns are re-rooted at Dir
+}
+
+// WorkspaceMode returns how workspaces are written, defaulting to separate
+func (c *Config) WorkspaceMode() string {
+	if c.WorkspaceOutput == "" {
+		return WorkspacesSeparate
+	}
+	return c.WorkspaceOutput
+}
+
+// ResolveWorkspaces expands the workspace globs and builds the config of every
+// matched directory. Workspaces are returned sorted by directory.
+func (c *Config) ResolveWorkspaces() ([]ResolvedWorkspace, error) {
+	var resolved []ResolvedWorkspace
+	names := make(map[string]string)
+	dirs := make(map[string]bool)
+
+	for _, ws := range c.Workspaces {
+		dirsOf, err := c.expandWorkspace(ws)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dir := range dirsOf {
+			if dirs[dir] {
+				continue
+			}
+			dirs[dir] = true
+
+			name := workspaceName(ws, dir)
+			if other, ok := names[name]; ok {
+				return nil, fmt.Errorf("workspaces %s and %s are both named %q", other, dir, name)
+			}
+			names[name] = dir
+
+			resolved = append(resolved, ResolvedWorkspace{
+				Name:   name,
+				Dir:    dir,
+				Config: c.workspaceConfig(ws, name, dir),
+			})
+		}
+	}
+
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].Dir < resolved[j].Dir
+	})
+
+	return resolved, nil
+}
+
+// expandWorkspace returns the directories matched by a workspace path
+func (c *Config) expandWorkspace(ws Workspace) ([]string, error) {
+	pattern := path.Clean(filepath.ToSlash(ws.Path))
+	if ws.Path == "" || path.IsAbs(pattern) || pattern == ".." || strings.HasPrefix(pattern, "../") {
+		return nil, fmt.Errorf("invalid workspace path %q (expected a directory inside project_path)", ws.Path)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(c.ProjectPath, filepath.FromSlash(pattern)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace path %q: %w", ws.Path, err)
+	}
+
+	var dirs []string
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		rel, err := filepath.Rel(c.ProjectPath, match)
+		if err != nil {
+			continue
+		}
+		dirs = append(dirs, filepath.ToSlash(rel))
+	}
+
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("workspace path %q does not match any directory", ws.Path)
+	}
+
+	return dirs, nil
+}
+
+// workspaceName returns the name of a workspace directory
+func workspaceName(ws Workspace, dir string) string {
+	switch {
+	case ws.Name == "":
+		return dir
+	case hasGlobMeta(ws.Path):
+		return ws.Name + "/" + path.Base(dir)
+	default:
+		return ws.Name
+	}
+}
+
+// validateName checks that the name of a workspace is a single directory name,
+// as separate outputs are written to a subdirectory named after it
+func (ws Workspace) validateName() error {
+	if ws.Name == "" {
+		return nil
+	}
+	if ws.Name == "." || ws.Name == ".." || strings.ContainsAny(ws.Name, `/\`) {
+		return fmt.Errorf("invalid workspace name %q (expected a directory name without path separators)", ws.Name)
+	}
+	return nil
+}
+
+// hasGlobMeta reports whether a path contains glob characters
+func hasGlobMeta(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// workspaceConfig builds the config of a workspace directory. The root settings and
+// exclusions are shared; patterns containing a directory are re-rooted at dir so
+// they keep matching relative to the workspace.
+func (c *Config) workspaceConfig(ws Workspace, name, dir string) *Config {
+	cfg := c.Clone()
+	cfg.ProjectName = name
+	cfg.Workspaces = nil
+
+	cfg.DataPatterns = prefixPatterns(override(ws.DataPatterns, c.DataPatterns), dir, true)
+	cfg.LocalPatterns = prefixPatterns(override(ws.LocalPatterns, c.LocalPatterns), dir, true)
+	cfg.MainLocalFiles = prefixPatterns(override(ws.MainLocalFiles, c.MainLocalFiles), dir, true)
+	cfg.ContextFiles = prefixPatterns(override(ws.ContextFiles, c.ContextFiles), dir, true)
+	cfg.OutlinePatterns = prefixPatterns(c.OutlinePatterns, dir, true)
+	cfg.IncludePatterns = prefixPatterns(override(ws.IncludePatterns, c.IncludePatterns), dir, true)
+
+	// Root exclusions such as build/** apply both at the root and inside every workspace
+	cfg.ExcludePatterns = appendUnique(cfg.ExcludePatterns, prefixPatterns(c.ExcludePatterns, dir, true)...)
+	cfg.ExcludePatterns = appendUnique(cfg.ExcludePatterns, prefixPatterns(ws.ExcludePatterns, dir, true)...)
+
+	return cfg
+}
+
+// override returns patterns when set, otherwise the fallback
+func override(patterns, fallback []string) []string {
+	if patterns != nil {
+		return patterns
+	}
+	return fallback
+}
@@ -0,0 +1,307 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Merged is the outcome of MergeYAML
+type Merged struct {
+	Data      []byte
+	Added     int    // Number of added keys and list entries
+	Rewritten bool   // The document was re-encoded instead of patched, see MergeYAML
+	Reason    string // Why the document was rewritten
+}
+
+// MergeYAML adds the keys and list entries of incoming that are missing from existing.
+// Values already present in existing are kept. The missing entries are inserted into
+// the text of existing, so its comments, blank lines and quoting are preserved. When
+// the entries cannot be inserted as text, e.g. into a flow sequence like [a, b], the
+// merged document is re-encoded instead, which keeps comments and key ordering but
+// normalizes the formatting.
+func MergeYAML(existing, incoming []byte) (Merged, error) {
+	var base, extra yaml.Node
+	if err := yaml.Unmarshal(existing, &base); err != nil {
+		return Merged{}, fmt.Errorf("failed to parse existing config: %w", err)
+	}
+	if err := yaml.Unmarshal(incoming, &extra); err != nil {
+		return Merged{}, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	// An empty existing file is replaced by the template
+	if len(base.Content) == 0 {
+		return Merged{Data: incoming}, nil
+	}
+	if len(extra.Content) == 0 {
+		return Merged{Data: existing}, nil
+	}
+
+	baseMap, extraMap := base.Content[0], extra.Content[0]
+	if baseMap.Kind != yaml.MappingNode || extraMap.Kind != yaml.MappingNode {
+		return Merged{}, fmt.Errorf("config must be a YAML mapping")
+	}
+
+	p := newPatch(existing)
+	added := p.mergeMappings(baseMap, extraMap, 0)
+	if added == 0 {
+		return Merged{Data: existing}, nil
+	}
+
+	reason := p.reason
+	if reason == "" {
+		data := p.apply()
+		if sameDocument(data, &base) {
+			return Merged{Data: data, Added: added}, nil
+		}
+		reason = "the new entries could not be inserted next to multi-line values"
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&base); err != nil {
+		return Merged{}, fmt.Errorf("failed to encode merged config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return Merged{}, fmt.Errorf("failed to encode merged config: %w", err)
+	}
+
+	return Merged{Data: restoreSectionBreaks(buf.Bytes()), Added: added, Rewritten: true, Reason: reason}, nil
+}
+
+// patch collects the lines inserted into an existing document
+type patch struct {
+	lines   []string
+	inserts []insertion
+	reason  string // Why an entry could not be placed in the text, if any
+}
+
+// insertion is a block of lines inserted after a line of the document, counted
+// from 1. Line 0 inserts at the end of the document. Blocks inserted after the
+// same line are ordered by depth, so the entries of a nested list come before
+// keys added to the mappings around it.
+type insertion struct {
+	after int
+	depth int // Nesting level of the mapping or list the lines belong to
+	lines []string
+}
+
+// fail records why the entries cannot be inserted into the text
+func (p *patch) fail(reason string) {
+	if p.reason == "" {
+		p.reason = reason
+	}
+}
+
+func newPatch(data []byte) *patch {
+	text := strings.TrimSuffix(string(data), "\n")
+	return &patch{lines: strings.Split(text, "\n")}
+}
+
+// mergeMappings copies missing keys and sequence entries from extra into base and
+// records where they go in the text. Missing top-level keys are appended to the
+// document, nested ones after the last line of their mapping. depth is 0 for the
+// top-level mapping.
+func (p *patch) mergeMappings(base, extra *yaml.Node, depth int) int {
+	added := 0
+
+	for i := 0; i+1 < len(extra.Content); i += 2 {
+		key, value := extra.Content[i], extra.Content[i+1]
+
+		existing := mappingValue(base, key.Value)
+		if existing == nil {
+			p.insertPair(base, key, value, depth)
+			base.Content = append(base.Content, key, value)
+			added++
+			continue
+		}
+
+		switch {
+		case existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			added += p.mergeSequences(existing, value, depth+1)
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			added += p.mergeMappings(existing, value, depth+1)
+		}
+	}
+
+	return added
+}
+
+// mergeSequences appends the scalar entries of extra that base does not contain
+func (p *patch) mergeSequences(base, extra *yaml.Node, depth int) int {
+	present := make(map[string]bool, len(base.Content))
+	for _, item := range base.Content {
+		if item.Kind == yaml.ScalarNode {
+			present[item.Value] = true
+		}
+	}
+
+	var items []*yaml.Node
+	for _, item := range extra.Content {
+		if item.Kind != yaml.ScalarNode || present[item.Value] {
+			continue
+		}
+		items = append(items, item)
+		present[item.Value] = true
+	}
+	if len(items) == 0 {
+		return 0
+	}
+
+	p.insertItems(base, items, depth)
+	base.Content = append(base.Content, items...)
+	return len(items)
+}
+
+// insertPair records a key missing from a block mapping
+func (p *patch) insertPair(mapping, key, value *yaml.Node, depth int) {
+	if mapping.Style&yaml.FlowStyle != 0 || len(mapping.Content) == 0 {
+		p.fail(fmt.Sprintf("%s is a flow-style mapping", key.Value))
+		return
+	}
+
+	pair := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}}
+	lines, err := encodeLines(pair)
+	if err != nil {
+		p.fail(fmt.Sprintf("%s cannot be encoded: %v", key.Value, err))
+		return
+	}
+
+	if depth == 0 {
+		// Top-level keys start a section of their own
+		p.inserts = append(p.inserts, insertion{after: 0, lines: append([]string{""}, lines...)})
+		return
+	}
+	indent := strings.Repeat(" ", mapping.Content[0].Column-1)
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	p.inserts = append(p.inserts, insertion{after: lastLine(mapping), depth: depth, lines: lines})
+}
+
+// insertItems records entries missing from a block sequence. They are written
+// like its first entry, after its last one.
+func (p *patch) insertItems(sequence *yaml.Node, items []*yaml.Node, depth int) {
+	if sequence.Style&yaml.FlowStyle != 0 || len(sequence.Content) == 0 {
+		p.fail(fmt.Sprintf("the list on line %d is written in flow style", sequence.Line))
+		return
+	}
+
+	first := sequence.Content[0]
+	line := p.lines[first.Line-1]
+	if first.Column-1 > len(line) || strings.TrimSpace(line[:first.Column-1]) != "-" {
+		p.fail(fmt.Sprintf("the list on line %d does not have one entry per line", sequence.Line))
+		return
+	}
+	prefix := line[:first.Column-1]
+
+	var lines []string
+	for _, item := range items {
+		scalar := *item
+		scalar.HeadComment, scalar.FootComment = "", ""
+		encoded, err := encodeLines(&scalar)
+		if err != nil || len(encoded) != 1 {
+			p.fail(fmt.Sprintf("the entry %q does not fit on one line", item.Value))
+			return
+		}
+		lines = append(lines, prefix+encoded[0])
+	}
+	p.inserts = append(p.inserts, insertion{after: lastLine(sequence), depth: depth, lines: lines})
+}
+
+// apply returns the document with the recorded insertions
+func (p *patch) apply() []byte {
+	at := func(ins insertion) int {
+		if ins.after == 0 {
+			return len(p.lines)
+		}
+		return ins.after
+	}
+	sort.SliceStable(p.inserts, func(i, j int) bool {
+		a, b := p.inserts[i], p.inserts[j]
+		if at(a) != at(b) {
+			return at(a) < at(b)
+		}
+		return a.depth > b.depth
+	})
+
+	var out []string
+	next := 0
+	for i, line := range p.lines {
+		out = append(out, line)
+		for next < len(p.inserts) && at(p.inserts[next]) == i+1 {
+			out = append(out, p.inserts[next].lines...)
+			next++
+		}
+	}
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+// lastLine returns the line of the last scalar of a node
+func lastLine(node *yaml.Node) int {
+	if (node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode) && len(node.Content) > 0 &&
+		node.Style&yaml.FlowStyle == 0 {
+		return lastLine(node.Content[len(node.Content)-1])
+	}
+	return node.Line
+}
+
+// encodeLines encodes a node as YAML lines with an indentation of 2
+func encodeLines(node *yaml.Node) ([]string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"), nil
+}
+
+// sameDocument reports whether data holds the same values as the merged node. It
+// guards against insertions that land in a multi-line value.
+func sameDocument(data []byte, merged *yaml.Node) bool {
+	var got, want any
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		return false
+	}
+	if err := merged.Decode(&want); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+// mappingValue returns the value node for key in a mapping node
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// restoreSectionBreaks re-inserts the blank line before top-level comment blocks that
+// the yaml.v3 encoder drops
+func restoreSectionBreaks(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+
+	var out []string
+	for i, line := range lines {
+		if i > 0 && strings.HasPrefix(line, "#") && !strings.HasPrefix(lines[i-1], "#") && lines[i-1] != "" {
+			out = append(out, "")
+		}
+		out = append(out, line)
+	}
+
+	return []byte(strings.Join(out, "\n"))
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMergeYAMLKeepsFormatting(t *testing.T) {
+	existing := `# My project
+project_path: .
+
+exclude_patterns:
+    - 'vendor/**'   # vendored
+
+    - "tmp/**"
+# end of excludes
+
+max_file_size_per_category:
+  data: 64KB
+`
+	incoming := `exclude_patterns:
+  - "tmp/**"
+  - "*.log" # logs
+max_file_size_per_category:
+  data: 1MB
+  locals: 8KB
+# Data files
+data_patterns:
+  - "data/**"
+`
+	want := `# My project
+project_path: .
+
+exclude_patterns:
+    - 'vendor/**'   # vendored
+
+    - "tmp/**"
+    - "*.log" # logs
+# end of excludes
+
+max_file_size_per_category:
+  data: 64KB
+  locals: 8KB
+
+# Data files
+data_patterns:
+  - "data/**"
+`
+
+	merged, err := MergeYAML([]byte(existing), []byte(incoming))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Added != 3 || merged.Rewritten {
+		t.Errorf("Added = %d, Rewritten = %v, want 3 entries patched in place", merged.Added, merged.Rewritten)
+	}
+	if string(merged.Data) != want {
+		t.Errorf("merged config:\n%s\nwant:\n%s", merged.Data, want)
+	}
+}
+
+func TestMergeYAMLExtendsLastKey(t *testing.T) {
+	existing := `project_path: .
+
+# Excluded
+exclude_patterns:
+  - "vendor/**"
+`
+	incoming := `exclude_patterns:
+  - "*.log"
+main_local_files:
+  - "main.go"
+`
+	want := `project_path: .
+
+# Excluded
+exclude_patterns:
+  - "vendor/**"
+  - "*.log"
+
+main_local_files:
+  - "main.go"
+`
+
+	merged, err := MergeYAML([]byte(existing), []byte(incoming))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Rewritten {
+		t.Errorf("config was rewritten: %s", merged.Reason)
+	}
+	if string(merged.Data) != want {
+		t.Errorf("merged config:\n%s\nwant:\n%s", merged.Data, want)
+	}
+}
+
+func TestMergeYAMLRewritesFlowLists(t *testing.T) {
+	existing := "project_path: .\n\nexclude_patterns: ['vendor/**']\n"
+	incoming := "exclude_patterns:\n  - \"*.log\"\n"
+
+	merged, err := MergeYAML([]byte(existing), []byte(incoming))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Added != 1 || !merged.Rewritten {
+		t.Errorf("Added = %d, Rewritten = %v, want 1 entry and a rewrite", merged.Added, merged.Rewritten)
+	}
+	if !strings.Contains(merged.Reason, "flow style") {
+		t.Errorf("Reason = %q, want it to name the flow-style list", merged.Reason)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(merged.Data, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.ExcludePatterns) != 2 || cfg.ExcludePatterns[1] != "*.log" {
+		t.Errorf("exclude_patterns = %v, want vendor/** and *.log", cfg.ExcludePatterns)
+	}
+}
+
+func TestMergeYAMLUnchanged(t *testing.T) {
+	existing := "exclude_patterns:\n  - vendor/**   # kept\n"
+	merged, err := MergeYAML([]byte(existing), []byte("exclude_patterns: [vendor/**]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Added != 0 || string(merged.Data) != existing {
+		t.Errorf("merged = %q (%d added), want the existing config", merged.Data, merged.Added)
+	}
+}
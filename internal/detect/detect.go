@@ -0,0 +1,220 @@
+package detect
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxDepth is how many directory levels below the project root are inspected
+const maxDepth = 2
+
+// Match describes a template detected in a project directory
+type Match struct {
+	Template string // Template name, e.g. "go" or "react"
+	Dir      string // Directory relative to the project root ("." for the root itself)
+	Reason   string // Marker that triggered the detection, e.g. "go.mod"
+}
+
+// rule detects a single template in a directory and returns the marker that matched
+type rule struct {
+	template string
+	family   string // Only the first matching rule of a family is used per directory
+	detect   func(dir string) (string, bool)
+	owns     []string // Subdirectories that belong to the detected project and are not inspected
+}
+
+// rules are evaluated in order; the first matching rule of a family claims the directory
+var rules = []rule{
+	{"go", "go", fileMarker("go.mod"), nil},
+	{"rust", "rust", fileMarker("Cargo.toml"), nil},
+	{"flutter", "flutter", fileMarker("pubspec.yaml"), flutterPlatforms},
+	{"elixir", "elixir", fileMarker("mix.exs"), nil},
+	{"cmake", "cmake", fileMarker("CMakeLists.txt"), nil},
+	{"terraform", "terraform", globMarker("*.tf"), nil},
+	{"dotnet", "dotnet", globMarker("*.sln", "*.csproj", "*.fsproj", "*.vbproj"), nil},
+	{"swift", "swift", globMarker("Package.swift", "Podfile", "*.xcodeproj", "*.xcworkspace"), nil},
+	{"android", "jvm", fileMarker("app/src/main/AndroidManifest.xml", "src/main/AndroidManifest.xml"), nil},
+	{"gradle", "jvm", fileMarker("build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"), nil},
+	{"maven", "jvm", fileMarker("pom.xml"), nil},
+	{"django", "python", fileMarker("manage.py"), nil},
+	{"python", "python", fileMarker("pyproject.toml", "requirements.txt", "setup.py", "setup.cfg", "Pipfile"), nil},
+	// Laravel and Rails ship a package.json for their asset pipelines
+	{"laravel", "web", allFilesMarker("composer.json", "artisan"), nil},
+	{"rails", "web", allFilesMarker("Gemfile", "config/application.rb"), nil},
+	{"node", "web", detectNode, nil},
+}
+
+// flutterPlatforms are the platform runner directories generated by Flutter
+var flutterPlatforms = []string{"android", "ios", "macos", "linux", "windows", "web"}
+
+// skipDirs are never descended into when looking for nested projects
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"build":        true,
+	"dist":         true,
+	"out":          true,
+	"target":       true,
+	"venv":         true,
+	"__pycache__":  true,
+	"deps":         true, // Elixir dependencies
+	"_build":       true,
+	"Pods":         true, // CocoaPods
+}
+
+// Detect inspects the project root and its subdirectories for known project markers
+func Detect(root string) ([]Match, error) {
+	var matches []Match
+
+	if err := detectDir(root, ".", 0, &matches); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// detectDir runs all rules against a single directory and recurses into its children
+func detectDir(root, rel string, depth int, matches *[]Match) error {
+	dir := filepath.Join(root, rel)
+
+	claimed := make(map[string]bool)
+	owned := make(map[string]bool)
+	for _, r := range rules {
+		if claimed[r.family] {
+			continue
+		}
+
+		reason, ok := r.detect(dir)
+		if !ok {
+			continue
+		}
+
+		template := r.template
+		if template == "node" {
+			template, reason = nodeTemplate(dir)
+		}
+
+		*matches = append(*matches, Match{Template: template, Dir: filepath.ToSlash(rel), Reason: reason})
+		claimed[r.family] = true
+		for _, sub := range r.owns {
+			owned[sub] = true
+		}
+	}
+
+	if depth >= maxDepth {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if depth == 0 {
+			return err
+		}
+		// Unreadable subdirectories are not fatal for detection
+		return nil
+	}
+
+	var children []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") || skipDirs[name] || owned[name] {
+			continue
+		}
+		children = append(children, name)
+	}
+	sort.Strings(children)
+
+	for _, child := range children {
+		if err := detectDir(root, filepath.Join(rel, child), depth+1, matches); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fileMarker matches when any of the given files exist in the directory
+func fileMarker(names ...string) func(string) (string, bool) {
+	return func(dir string) (string, bool) {
+		for _, name := range names {
+			if fileExists(filepath.Join(dir, name)) {
+				return name, true
+			}
+		}
+		return "", false
+	}
+}
+
+// allFilesMarker matches when all of the given files exist in the directory
+func allFilesMarker(names ...string) func(string) (string, bool) {
+	return func(dir string) (string, bool) {
+		for _, name := range names {
+			if !fileExists(filepath.Join(dir, name)) {
+				return "", false
+			}
+		}
+		return strings.Join(names, " + "), true
+	}
+}
+
+// globMarker matches when any file or directory in dir matches one of the patterns
+func globMarker(patterns ...string) func(string) (string, bool) {
+	return func(dir string) (string, bool) {
+		for _, pattern := range patterns {
+			matches, err := filepath.Glob(filepath.Join(dir, pattern))
+			if err == nil && len(matches) > 0 {
+				return filepath.Base(matches[0]), true
+			}
+		}
+		return "", false
+	}
+}
+
+// detectNode matches directories containing a package.json
+func detectNode(dir string) (string, bool) {
+	return fileMarker("package.json")(dir)
+}
+
+// nodeTemplate picks the JavaScript template based on package.json dependencies
+func nodeTemplate(dir string) (string, string) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return "nodejs", "package.json"
+	}
+
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return "nodejs", "package.json"
+	}
+
+	hasDep := func(name string) bool {
+		_, ok := pkg.Dependencies[name]
+		if !ok {
+			_, ok = pkg.DevDependencies[name]
+		}
+		return ok
+	}
+
+	switch {
+	case hasDep("next"):
+		return "nextjs", "package.json (next)"
+	case hasDep("vue"):
+		return "vue", "package.json (vue)"
+	case hasDep("react"):
+		return "react", "package.json (react)"
+	default:
+		return "nodejs", "package.json"
+	}
+}
+
+// fileExists reports whether path exists and is a regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
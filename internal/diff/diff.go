@@ -0,0 +1,133 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+// op is a single line-level edit
+type op struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// Unified returns a unified diff between a and b, or an empty string if they are equal
+func Unified(a, b, nameA, nameB string) string {
+	if a == b {
+		return ""
+	}
+
+	ops := lineOps(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are within 2*contextLines of each other
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*contextLines {
+				break
+			}
+		}
+
+		from := max(start-contextLines, 0)
+		to := min(end+contextLines, len(ops))
+		writeHunk(&sb, ops, from, to)
+		start = to
+	}
+
+	return sb.String()
+}
+
+// writeHunk writes ops[from:to] with a hunk header
+func writeHunk(sb *strings.Builder, ops []op, from, to int) {
+	lineA, lineB := 1, 1
+	for _, o := range ops[:from] {
+		if o.kind != '+' {
+			lineA++
+		}
+		if o.kind != '-' {
+			lineB++
+		}
+	}
+
+	countA, countB := 0, 0
+	for _, o := range ops[from:to] {
+		if o.kind != '+' {
+			countA++
+		}
+		if o.kind != '-' {
+			countB++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", lineA, countA, lineB, countB)
+	for _, o := range ops[from:to] {
+		fmt.Fprintf(sb, "%c%s\n", o.kind, o.line)
+	}
+}
+
+// lineOps computes the edit script between two line slices using the longest common subsequence
+func lineOps(a, b []string) []op {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+
+	return ops
+}
+
+// splitLines splits text into lines without the trailing empty line
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,166 @@
+// Package gosrc summarizes Go source files with go/parser for the generated
+// documents.
+package gosrc
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Package is the exported API of a Go package
+type Package struct {
+	Dir      string   // Directory relative to the project directory, "." for the root
+	Name     string   // Package name
+	Synopsis string   // First sentence of the package documentation
+	Decls    []string // Exported declarations, one line each like the go doc summary
+}
+
+// API returns the exported API of the Go packages among files, which are paths
+// relative to root. Test files and files that do not parse are skipped, as are
+// packages without documentation or exported declarations.
+func API(root string, files []string) []Package {
+	dirs := make(map[string][]string)
+	for _, file := range files {
+		if path.Ext(file) != ".go" || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		dirs[path.Dir(file)] = append(dirs[path.Dir(file)], file)
+	}
+
+	names := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		names = append(names, dir)
+	}
+	sort.Strings(names)
+
+	var packages []Package
+	for _, dir := range names {
+		pkg, ok := packageAPI(root, dir, dirs[dir])
+		if ok {
+			packages = append(packages, pkg)
+		}
+	}
+	return packages
+}
+
+// packageAPI summarizes the files of a directory. Only files of the package named
+// by the first file that parses are used, so stray build-ignored files of another
+// package do not break the summary.
+func packageAPI(root, dir string, files []string) (Package, bool) {
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, filepath.Join(root, filepath.FromSlash(file)), nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		if len(parsed) > 0 && f.Name.Name != parsed[0].Name.Name {
+			continue
+		}
+		parsed = append(parsed, f)
+	}
+	if len(parsed) == 0 {
+		return Package{}, false
+	}
+
+	docs, err := doc.NewFromFiles(fset, parsed, dir)
+	if err != nil {
+		return Package{}, false
+	}
+
+	pkg := Package{Dir: dir, Name: docs.Name, Synopsis: docs.Synopsis(docs.Doc)}
+	pkg.Decls = append(pkg.Decls, values(docs.Consts)...)
+	pkg.Decls = append(pkg.Decls, values(docs.Vars)...)
+	for _, fn := range docs.Funcs {
+		pkg.Decls = append(pkg.Decls, signature(fset, fn.Decl))
+	}
+	for _, typ := range docs.Types {
+		pkg.Decls = append(pkg.Decls, typeSummary(fset, typ.Decl))
+		pkg.Decls = append(pkg.Decls, values(typ.Consts)...)
+		pkg.Decls = append(pkg.Decls, values(typ.Vars)...)
+		for _, fn := range typ.Funcs {
+			pkg.Decls = append(pkg.Decls, signature(fset, fn.Decl))
+		}
+		for _, fn := range typ.Methods {
+			pkg.Decls = append(pkg.Decls, signature(fset, fn.Decl))
+		}
+	}
+
+	if pkg.Synopsis == "" && len(pkg.Decls) == 0 {
+		return Package{}, false
+	}
+	return pkg, true
+}
+
+// values summarizes constant or variable declarations by their names
+func values(list []*doc.Value) []string {
+	var decls []string
+	for _, value := range list {
+		decls = append(decls, value.Decl.Tok.String()+" "+strings.Join(value.Names, ", "))
+	}
+	return decls
+}
+
+// signature returns the declaration of a function without its body
+func signature(fset *token.FileSet, decl *ast.FuncDecl) string {
+	return printNode(fset, &ast.FuncDecl{Recv: decl.Recv, Name: decl.Name, Type: decl.Type})
+}
+
+// elidedBody stands in for struct and interface bodies while printing type summaries
+const elidedBody = "_elided_"
+
+// typeSummary returns the declaration of a type with the fields of structs and
+// the methods of interfaces elided
+func typeSummary(fset *token.FileSet, decl *ast.GenDecl) string {
+	var lines []string
+	for _, spec := range decl.Specs {
+		typ, ok := spec.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		summary := *typ
+		summary.Doc, summary.Comment = nil, nil
+
+		// Print a placeholder in place of elided struct and interface bodies
+		elided := ""
+		switch t := typ.Type.(type) {
+		case *ast.StructType:
+			elided = "struct{}"
+			if t.Fields != nil && len(t.Fields.List) > 0 {
+				elided = "struct{ ... }"
+			}
+		case *ast.InterfaceType:
+			elided = "interface{}"
+			if t.Methods != nil && len(t.Methods.List) > 0 {
+				elided = "interface{ ... }"
+			}
+		}
+		if elided != "" {
+			summary.Type = ast.NewIdent(elidedBody)
+		}
+
+		line := printNode(fset, &ast.GenDecl{Tok: token.TYPE, Specs: []ast.Spec{&summary}})
+		if elided != "" {
+			line = strings.Replace(line, elidedBody, elided, 1)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// printNode prints a syntax tree node
+func printNode(fset *token.FileSet, node any) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
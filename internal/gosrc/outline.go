@@ -0,0 +1,186 @@
+package gosrc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+)
+
+// Outline returns a Go source file with the bodies of its functions and methods
+// removed, including the bodies of function literals assigned to variables. The
+// package documentation, imports, declarations and doc comments are kept; comments
+// inside the removed bodies are dropped with them. Unexported declarations are
+// dropped too, unless unexported is set.
+func Outline(filename string, src []byte, unexported bool) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	var removed []span
+	decls := file.Decls[:0]
+	for _, decl := range file.Decls {
+		if s := declSpan(decl); !unexported && !filterDecl(decl, &removed) {
+			removed = append(removed, s)
+			continue
+		}
+		decls = append(decls, decl)
+
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Body != nil {
+				removed = append(removed, span{decl.Body.Lbrace + 1, decl.Body.Rbrace})
+				decl.Body = nil
+			}
+		case *ast.GenDecl:
+			ast.Inspect(decl, func(node ast.Node) bool {
+				if lit, ok := node.(*ast.FuncLit); ok {
+					removed = append(removed, span{lit.Body.Lbrace + 1, lit.Body.Rbrace})
+					lit.Body = elide(lit.Body)
+					return false
+				}
+				return true
+			})
+		}
+	}
+	file.Decls = decls
+
+	comments := file.Comments[:0]
+	for _, group := range file.Comments {
+		if !inside(group, removed) {
+			comments = append(comments, group)
+		}
+	}
+	file.Comments = comments
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, fmt.Errorf("failed to print outline of %s: %w", filename, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// span is a range of the source whose comments are dropped from the outline
+type span struct {
+	pos, end token.Pos
+}
+
+// inside reports whether a comment lies within one of the removed spans
+func inside(group *ast.CommentGroup, removed []span) bool {
+	for _, s := range removed {
+		if group.Pos() >= s.pos && group.End() <= s.end {
+			return true
+		}
+	}
+	return false
+}
+
+// elide replaces a function literal body with "{ ... }" on the line of its opening
+// brace
+func elide(body *ast.BlockStmt) *ast.BlockStmt {
+	return &ast.BlockStmt{
+		Lbrace: body.Lbrace,
+		List:   []ast.Stmt{&ast.ExprStmt{X: ast.NewIdent("...")}},
+		Rbrace: body.Lbrace + 1,
+	}
+}
+
+// filterDecl reports whether a declaration is part of the exported API. The
+// unexported specs of a kept type, constant or variable declaration are removed
+// from it and their spans recorded.
+func filterDecl(decl ast.Decl, removed *[]span) bool {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		if decl.Recv != nil && len(decl.Recv.List) > 0 && !ast.IsExported(receiverType(decl.Recv.List[0].Type)) {
+			return false
+		}
+		return decl.Name.IsExported()
+	case *ast.GenDecl:
+		if decl.Tok == token.IMPORT {
+			return true
+		}
+		specs := decl.Specs[:0]
+		for _, spec := range decl.Specs {
+			if exportedSpec(spec) {
+				specs = append(specs, spec)
+			} else {
+				*removed = append(*removed, specSpan(spec))
+			}
+		}
+		decl.Specs = specs
+		return len(specs) > 0
+	}
+	return true
+}
+
+// exportedSpec reports whether a type spec or one of the names of a value spec
+// is exported
+func exportedSpec(spec ast.Spec) bool {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return spec.Name.IsExported()
+	case *ast.ValueSpec:
+		for _, name := range spec.Names {
+			if name.IsExported() {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// receiverType returns the name of the base type of a method receiver
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// declSpan returns the span of a declaration with its doc comment. The line
+// comments of its specs are covered by the spans filterDecl records.
+func declSpan(decl ast.Decl) span {
+	s := span{decl.Pos(), decl.End()}
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		if decl.Doc != nil {
+			s.pos = decl.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if decl.Doc != nil {
+			s.pos = decl.Doc.Pos()
+		}
+	}
+	return s
+}
+
+// specSpan returns the span of a spec with its doc and line comments
+func specSpan(spec ast.Spec) span {
+	s := span{spec.Pos(), spec.End()}
+	var doc, comment *ast.CommentGroup
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		doc, comment = spec.Doc, spec.Comment
+	case *ast.ValueSpec:
+		doc, comment = spec.Doc, spec.Comment
+	}
+	if doc != nil {
+		s.pos = doc.Pos()
+	}
+	if comment != nil {
+		s.end = comment.End()
+	}
+	return s
+}
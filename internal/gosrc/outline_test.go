@@ -0,0 +1,93 @@
+package gosrc
+
+import (
+	"strings"
+	"testing"
+)
+
+const outlineSource = `// Package demo is a demo
+package demo
+
+import "fmt"
+
+// Greet says hello
+func Greet(name string) string {
+	// inside the body
+	return fmt.Sprint("hello ", name)
+}
+
+// Handler handles requests
+var Handler = func(name string) {
+	// inside the literal
+	fmt.Println(name)
+}
+
+// helper is unexported
+func helper() {}
+
+// T is exported
+type T struct{ N int }
+
+// String formats T
+func (t *T) String() string { return "t" }
+
+func (t *T) reset() {}
+
+type state int
+
+func (s state) Next() state { return s + 1 }
+
+const (
+	// Max is exported
+	Max = 10
+	min = 1 // unexported
+)
+
+var cache = map[string]int{}
+`
+
+func TestOutline(t *testing.T) {
+	outline, err := Outline("demo.go", []byte(outlineSource), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(outline)
+
+	for _, want := range []string{
+		"// Package demo is a demo", `import "fmt"`,
+		"// Greet says hello\nfunc Greet(name string) string\n",
+		"var Handler = func(name string) { ... }",
+		"type T struct{ N int }", "func (t *T) String() string\n", "Max = 10",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("outline is missing %q:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{
+		"inside", "fmt.Println", "helper", "reset", "state", "min", "cache",
+	} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("outline contains %q:\n%s", unwanted, text)
+		}
+	}
+}
+
+func TestOutlineUnexported(t *testing.T) {
+	outline, err := Outline("demo.go", []byte(outlineSource), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(outline)
+
+	for _, want := range []string{
+		"// helper is unexported\nfunc helper()\n", "func (t *T) reset()", "type state int",
+		"min = 1 // unexported", "var cache = map[string]int{}",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("outline is missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "inside") {
+		t.Errorf("outline contains comments of removed bodies:\n%s", text)
+	}
+}
@@ -0,0 +1,323 @@
+package language
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Other is the language of files that are not recognised
+const Other = "Other"
+
+// sniffLen is the number of leading bytes inspected for shebangs and binary data
+const sniffLen = 8000
+
+// filenames maps well-known file names to their language
+var filenames = map[string]string{
+	"Dockerfile":        "Dockerfile",
+	"Containerfile":     "Dockerfile",
+	"Makefile":          "Makefile",
+	"GNUmakefile":       "Makefile",
+	"makefile":          "Makefile",
+	"CMakeLists.txt":    "CMake",
+	"Jenkinsfile":       "Groovy",
+	"Vagrantfile":       "Ruby",
+	"Gemfile":           "Ruby",
+	"Rakefile":          "Ruby",
+	"Podfile":           "Ruby",
+	"Fastfile":          "Ruby",
+	"Brewfile":          "Ruby",
+	"Procfile":          "Procfile",
+	"BUILD":             "Starlark",
+	"BUILD.bazel":       "Starlark",
+	"WORKSPACE":         "Starlark",
+	"Justfile":          "Just",
+	"justfile":          "Just",
+	"go.mod":            "Go Module",
+	"go.sum":            "Go Checksums",
+	".gitignore":        "Ignore List",
+	".dockerignore":     "Ignore List",
+	".gitattributes":    "Git Attributes",
+	".editorconfig":     "EditorConfig",
+	".bashrc":           "Shell",
+	".bash_profile":     "Shell",
+	".zshrc":            "Shell",
+	".profile":          "Shell",
+	"LICENSE":           "Text",
+	"COPYING":           "Text",
+	".env":              "Dotenv",
+	"artisan":           "PHP",
+	"composer.lock":     "JSON",
+	"package-lock.json": "JSON",
+}
+
+// prefixes maps file name prefixes to their language, e.g. Dockerfile.dev
+var prefixes = map[string]string{
+	"Dockerfile.": "Dockerfile",
+	"Makefile.":   "Makefile",
+	".env.":       "Dotenv",
+}
+
+// extensions maps lower-case file extensions to their language
+var extensions = map[string]string{
+	".go":         "Go",
+	".rs":         "Rust",
+	".c":          "C",
+	".h":          "C",
+	".cc":         "C++",
+	".cpp":        "C++",
+	".cxx":        "C++",
+	".hh":         "C++",
+	".hpp":        "C++",
+	".hxx":        "C++",
+	".m":          "Objective-C",
+	".mm":         "Objective-C++",
+	".cs":         "C#",
+	".fs":         "F#",
+	".vb":         "Visual Basic",
+	".java":       "Java",
+	".kt":         "Kotlin",
+	".kts":        "Kotlin",
+	".scala":      "Scala",
+	".groovy":     "Groovy",
+	".gradle":     "Groovy",
+	".clj":        "Clojure",
+	".swift":      "Swift",
+	".dart":       "Dart",
+	".py":         "Python",
+	".pyi":        "Python",
+	".rb":         "Ruby",
+	".erb":        "ERB",
+	".php":        "PHP",
+	".pl":         "Perl",
+	".pm":         "Perl",
+	".lua":        "Lua",
+	".r":          "R",
+	".jl":         "Julia",
+	".ex":         "Elixir",
+	".exs":        "Elixir",
+	".erl":        "Erlang",
+	".hs":         "Haskell",
+	".ml":         "OCaml",
+	".zig":        "Zig",
+	".nim":        "Nim",
+	".js":         "JavaScript",
+	".mjs":        "JavaScript",
+	".cjs":        "JavaScript",
+	".jsx":        "JavaScript",
+	".ts":         "TypeScript",
+	".mts":        "TypeScript",
+	".cts":        "TypeScript",
+	".tsx":        "TypeScript",
+	".vue":        "Vue",
+	".svelte":     "Svelte",
+	".html":       "HTML",
+	".htm":        "HTML",
+	".css":        "CSS",
+	".scss":       "SCSS",
+	".sass":       "Sass",
+	".less":       "Less",
+	".sh":         "Shell",
+	".bash":       "Shell",
+	".zsh":        "Shell",
+	".fish":       "Fish",
+	".ps1":        "PowerShell",
+	".bat":        "Batch",
+	".cmd":        "Batch",
+	".sql":        "SQL",
+	".graphql":    "GraphQL",
+	".gql":        "GraphQL",
+	".proto":      "Protocol Buffers",
+	".tf":         "HCL",
+	".tfvars":     "HCL",
+	".hcl":        "HCL",
+	".nix":        "Nix",
+	".cmake":      "CMake",
+	".mk":         "Makefile",
+	".bzl":        "Starlark",
+	".json":       "JSON",
+	".jsonc":      "JSON",
+	".arb":        "JSON",
+	".yaml":       "YAML",
+	".yml":        "YAML",
+	".toml":       "TOML",
+	".ini":        "INI",
+	".cfg":        "INI",
+	".conf":       "INI",
+	".properties": "Properties",
+	".xml":        "XML",
+	".plist":      "XML",
+	".svg":        "SVG",
+	".csv":        "CSV",
+	".tsv":        "TSV",
+	".md":         "Markdown",
+	".mdx":        "MDX",
+	".rst":        "reStructuredText",
+	".adoc":       "AsciiDoc",
+	".tex":        "TeX",
+	".txt":        "Text",
+	".tmpl":       "Go Template",
+	".gotmpl":     "Go Template",
+	".twig":       "Twig",
+	".hbs":        "Handlebars",
+	".mustache":   "Mustache",
+	".blade.php":  "Blade",
+}
+
+// interpreters maps shebang interpreters to their language
+var interpreters = map[string]string{
+	"sh":      "Shell",
+	"bash":    "Shell",
+	"zsh":     "Shell",
+	"dash":    "Shell",
+	"ksh":     "Shell",
+	"fish":    "Fish",
+	"python":  "Python",
+	"python2": "Python",
+	"python3": "Python",
+	"ruby":    "Ruby",
+	"perl":    "Perl",
+	"php":     "PHP",
+	"node":    "JavaScript",
+	"deno":    "TypeScript",
+	"bun":     "JavaScript",
+	"ts-node": "TypeScript",
+	"lua":     "Lua",
+	"Rscript": "R",
+	"pwsh":    "PowerShell",
+	"elixir":  "Elixir",
+	"escript": "Erlang",
+	"make":    "Makefile",
+}
+
+// Detect returns the language of a file from its name alone. It returns Other
+// when the name is not enough, e.g. for scripts identified by their shebang.
+func Detect(path string) string {
+	name := filepath.Base(path)
+	if lang, ok := filenames[name]; ok {
+		return lang
+	}
+	for prefix, lang := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return lang
+		}
+	}
+
+	lower := strings.ToLower(name)
+	// Compound extensions such as .blade.php take precedence over the last one
+	if i := strings.Index(lower[1:], "."); i >= 0 {
+		if lang, ok := extensions[lower[i+1:]]; ok {
+			return lang
+		}
+	}
+	if lang, ok := extensions[filepath.Ext(lower)]; ok {
+		return lang
+	}
+	return Other
+}
+
+// FromShebang returns the language of a "#!" line, or Other
+func FromShebang(line string) string {
+	line, ok := strings.CutPrefix(strings.TrimSpace(line), "#!")
+	if !ok {
+		return Other
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Other
+	}
+	interpreter := filepath.Base(fields[0])
+	// #!/usr/bin/env [-S] python3
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = field
+				break
+			}
+		}
+	}
+
+	if lang, ok := interpreters[interpreter]; ok {
+		return lang
+	}
+	// Versioned interpreters such as python3.12 or perl5
+	if lang, ok := interpreters[strings.TrimRight(interpreter, "0123456789.")]; ok {
+		return lang
+	}
+	return Other
+}
+
+// bytesPerToken is the average number of bytes per token used for estimates. It
+// matches the usual rule of thumb for English text and source code.
+const bytesPerToken = 4
+
+// EstimateTokens returns a rough estimate of the number of tokens in size bytes
+func EstimateTokens(size int64) int64 {
+	return (size + bytesPerToken - 1) / bytesPerToken
+}
+
+// File describes a project file
+type File struct {
+	Language  string
+	Bytes     int64
+	Lines     Lines
+	Binary    bool // Binary files have no lines
+	Uncounted bool // The file exceeds the inspection limit and its lines were not counted
+}
+
+// Inspect reads a file and returns its language, size and line counts. The
+// language is detected from the file name, then from a shebang line.
+func Inspect(path string) (File, error) {
+	return InspectUpTo(path, 0)
+}
+
+// InspectUpTo is like Inspect, but files larger than limit bytes are not read past
+// their first bytes: their size, language and binary flag are reported without
+// line counts. A limit of 0 reads every file.
+func InspectUpTo(path string, limit int64) (File, error) {
+	file := File{Language: Detect(path)}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return file, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return file, err
+	}
+	file.Bytes = info.Size()
+
+	reader := bufio.NewReaderSize(f, sniffLen)
+	head, _ := reader.Peek(sniffLen)
+	if bytes.IndexByte(head, 0) >= 0 {
+		file.Binary = true
+		return file, nil
+	}
+
+	if file.Language == Other && bytes.HasPrefix(head, []byte("#!")) {
+		line, _, _ := bytes.Cut(head, []byte("\n"))
+		file.Language = FromShebang(string(line))
+	}
+
+	if limit > 0 && file.Bytes > limit {
+		file.Uncounted = true
+		return file, nil
+	}
+
+	syntax, known := SyntaxOf(file.Language)
+	lines := counter{syntax: syntax, known: known}
+	scanner := bufio.NewScanner(reader)
+	// Tolerate long lines (minified files)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines.add(scanner.Text())
+	}
+	file.Lines = lines.lines
+	return file, scanner.Err()
+}
@@ -0,0 +1,238 @@
+package language
+
+import "strings"
+
+// Syntax describes the comments of a language
+type Syntax struct {
+	Line  []string    // Line comment markers, e.g. "//"
+	Block [][2]string // Block comment start and end markers, e.g. "/*" and "*/"
+}
+
+// Comment syntaxes shared by several languages
+var (
+	cStyle    = Syntax{Line: []string{"//"}, Block: [][2]string{{"/*", "*/"}}}
+	hashStyle = Syntax{Line: []string{"#"}}
+	xmlStyle  = Syntax{Block: [][2]string{{"<!--", "-->"}}}
+	noComment = Syntax{}
+)
+
+// syntaxes maps languages to their comment syntax. Languages without comments are
+// listed with an empty syntax so that their lines are still counted as code.
+var syntaxes = map[string]Syntax{
+	"Go":               cStyle,
+	"Go Module":        {Line: []string{"//"}},
+	"Rust":             cStyle,
+	"C":                cStyle,
+	"C++":              cStyle,
+	"Objective-C":      cStyle,
+	"Objective-C++":    cStyle,
+	"C#":               cStyle,
+	"F#":               {Line: []string{"//"}, Block: [][2]string{{"(*", "*)"}}},
+	"Visual Basic":     {Line: []string{"'"}},
+	"Java":             cStyle,
+	"Kotlin":           cStyle,
+	"Scala":            cStyle,
+	"Groovy":           cStyle,
+	"Clojure":          {Line: []string{";"}},
+	"Swift":            cStyle,
+	"Dart":             cStyle,
+	"Python":           {Line: []string{"#"}, Block: [][2]string{{`"""`, `"""`}, {"'''", "'''"}}},
+	"Ruby":             {Line: []string{"#"}, Block: [][2]string{{"=begin", "=end"}}},
+	"ERB":              {Block: [][2]string{{"<%#", "%>"}, {"<!--", "-->"}}},
+	"PHP":              {Line: []string{"//", "#"}, Block: [][2]string{{"/*", "*/"}}},
+	"Blade":            {Block: [][2]string{{"{{--", "--}}"}, {"<!--", "-->"}}},
+	"Perl":             {Line: []string{"#"}, Block: [][2]string{{"=pod", "=cut"}}},
+	"Lua":              {Line: []string{"--"}, Block: [][2]string{{"--[[", "]]"}}},
+	"R":                hashStyle,
+	"Julia":            {Line: []string{"#"}, Block: [][2]string{{"#=", "=#"}}},
+	"Elixir":           hashStyle,
+	"Erlang":           {Line: []string{"%"}},
+	"Haskell":          {Line: []string{"--"}, Block: [][2]string{{"{-", "-}"}}},
+	"OCaml":            {Block: [][2]string{{"(*", "*)"}}},
+	"Zig":              {Line: []string{"//"}},
+	"Nim":              {Line: []string{"#"}, Block: [][2]string{{"#[", "]#"}}},
+	"JavaScript":       cStyle,
+	"TypeScript":       cStyle,
+	"Vue":              {Line: []string{"//"}, Block: [][2]string{{"<!--", "-->"}, {"/*", "*/"}}},
+	"Svelte":           {Line: []string{"//"}, Block: [][2]string{{"<!--", "-->"}, {"/*", "*/"}}},
+	"HTML":             xmlStyle,
+	"XML":              xmlStyle,
+	"SVG":              xmlStyle,
+	"Markdown":         xmlStyle,
+	"MDX":              xmlStyle,
+	"CSS":              {Block: [][2]string{{"/*", "*/"}}},
+	"SCSS":             cStyle,
+	"Sass":             cStyle,
+	"Less":             cStyle,
+	"Shell":            hashStyle,
+	"Fish":             hashStyle,
+	"PowerShell":       {Line: []string{"#"}, Block: [][2]string{{"<#", "#>"}}},
+	"Batch":            {Line: []string{"::", "REM ", "rem ", "@REM ", "@rem "}},
+	"SQL":              {Line: []string{"--"}, Block: [][2]string{{"/*", "*/"}}},
+	"GraphQL":          hashStyle,
+	"Protocol Buffers": cStyle,
+	"HCL":              {Line: []string{"#", "//"}, Block: [][2]string{{"/*", "*/"}}},
+	"Nix":              {Line: []string{"#"}, Block: [][2]string{{"/*", "*/"}}},
+	"CMake":            hashStyle,
+	"Makefile":         hashStyle,
+	"Dockerfile":       hashStyle,
+	"Starlark":         hashStyle,
+	"Just":             hashStyle,
+	"Procfile":         hashStyle,
+	"YAML":             hashStyle,
+	"TOML":             hashStyle,
+	"INI":              {Line: []string{";", "#"}},
+	"Properties":       {Line: []string{"#", "!"}},
+	"EditorConfig":     {Line: []string{"#", ";"}},
+	"Dotenv":           hashStyle,
+	"Ignore List":      hashStyle,
+	"Git Attributes":   hashStyle,
+	"TeX":              {Line: []string{"%"}},
+	"Go Template":      {Block: [][2]string{{"{{/*", "*/}}"}, {"{{- /*", "*/ -}}"}}},
+	"Twig":             {Block: [][2]string{{"{#", "#}"}}},
+	"Handlebars":       {Block: [][2]string{{"{{!--", "--}}"}, {"{{!", "}}"}}},
+	"Mustache":         {Block: [][2]string{{"{{!", "}}"}}},
+	"JSON":             noComment,
+	"CSV":              noComment,
+	"TSV":              noComment,
+	"Text":             noComment,
+	"Go Checksums":     noComment,
+	"reStructuredText": noComment,
+	"AsciiDoc":         noComment,
+}
+
+// SyntaxOf returns the comment syntax of a language, and false for languages
+// whose lines cannot be classified
+func SyntaxOf(lang string) (Syntax, bool) {
+	syntax, ok := syntaxes[lang]
+	return syntax, ok
+}
+
+// Lines counts the lines of a file. Code, comment and blank lines are only
+// counted for languages with a known syntax; they always add up to Total then.
+type Lines struct {
+	Total   int `json:"total"`
+	Code    int `json:"code"`
+	Comment int `json:"comment"`
+	Blank   int `json:"blank"`
+}
+
+// Add adds the counts of other to l
+func (l *Lines) Add(other Lines) {
+	l.Total += other.Total
+	l.Code += other.Code
+	l.Comment += other.Comment
+	l.Blank += other.Blank
+}
+
+// Classified reports whether the lines were split into code, comment and blank lines
+func (l Lines) Classified() bool {
+	return l.Code+l.Comment+l.Blank > 0
+}
+
+// counter classifies the lines of a file one at a time
+type counter struct {
+	syntax Syntax
+	known  bool
+	end    string // End marker of the block comment the previous line ended in
+	lines  Lines
+}
+
+// add classifies a line. Lines with both code and a comment count as code.
+// Comment markers inside string literals are not recognised, so counts are an
+// approximation in the spirit of cloc.
+func (c *counter) add(line string) {
+	c.lines.Total++
+	if !c.known {
+		return
+	}
+
+	rest := strings.TrimSpace(line)
+	if rest == "" {
+		c.lines.Blank++
+		return
+	}
+
+	code, comment := false, false
+	for {
+		rest = strings.TrimSpace(rest)
+		if c.end != "" {
+			comment = true
+			i := strings.Index(rest, c.end)
+			if i < 0 {
+				break
+			}
+			rest, c.end = rest[i+len(c.end):], ""
+			continue
+		}
+		if rest == "" {
+			break
+		}
+		// Block markers first: Lua's "--[[" and Julia's "#=" extend their line markers
+		if start, end, ok := c.syntax.blockStart(rest); ok {
+			comment = true
+			rest, c.end = rest[len(start):], end
+			continue
+		}
+		if c.syntax.lineComment(rest) {
+			comment = true
+			break
+		}
+
+		code = true
+		i, start, end := c.syntax.nextBlock(rest)
+		if i < 0 {
+			break
+		}
+		rest, c.end = rest[i+len(start):], end
+	}
+
+	switch {
+	case code:
+		c.lines.Code++
+	case comment:
+		c.lines.Comment++
+	default:
+		c.lines.Blank++
+	}
+}
+
+// lineComment reports whether text starts with a line comment
+func (s Syntax) lineComment(text string) bool {
+	for _, marker := range s.Line {
+		if strings.HasPrefix(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockStart returns the block comment that text starts with. Longer start markers
+// are preferred so that e.g. "{{!--" wins over "{{!".
+func (s Syntax) blockStart(text string) (string, string, bool) {
+	var start, end string
+	for _, block := range s.Block {
+		if strings.HasPrefix(text, block[0]) && len(block[0]) > len(start) {
+			start, end = block[0], block[1]
+		}
+	}
+	return start, end, start != ""
+}
+
+// nextBlock returns the position and markers of the first block comment started
+// in text, or -1 when there is none or a line comment comes first
+func (s Syntax) nextBlock(text string) (int, string, string) {
+	pos, start, end := -1, "", ""
+	for _, block := range s.Block {
+		i := strings.Index(text, block[0])
+		if i >= 0 && (pos < 0 || i < pos) {
+			pos, start, end = i, block[0], block[1]
+		}
+	}
+	for _, marker := range s.Line {
+		if i := strings.Index(text, marker); i >= 0 && i < pos {
+			return -1, "", ""
+		}
+	}
+	return pos, start, end
+}
@@ -0,0 +1,207 @@
+package markdown
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+	"github.com/adil-chbada/extract-cli/internal/gosrc"
+)
+
+// binarySniffLen is the number of leading bytes inspected to detect binary files
+const binarySniffLen = 8000
+
+// fileContents is the embeddable text of a file
+type fileContents struct {
+	embedded bool     // Lines holds the (possibly truncated) file
+	lines    []string // First and last keep lines of truncated files
+	omitted  int      // Lines left out of a truncated file
+	keep     int      // Lines kept at each end of a truncated file
+	note     string   // Shown instead of or above the lines, e.g. why they are missing
+}
+
+// readContents reads a file for embedding. Files larger than limit are skipped or
+// truncated to their first and last lines depending on policy.
+func readContents(path string, size, limit int64, policy string, keepLines int) fileContents {
+	oversized := limit > 0 && size > limit
+	if oversized && policy != config.OversizedTruncate {
+		return fileContents{note: fmt.Sprintf("⚠️ Contents skipped: file exceeds max_file_size (%s > %s)",
+			formatFileSize(size), formatFileSize(limit))}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fileContents{note: fmt.Sprintf("⚠️ Contents unavailable: %v", err)}
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, binarySniffLen)
+	if head, _ := reader.Peek(binarySniffLen); isBinary(head) {
+		return fileContents{note: "Binary file, contents omitted."}
+	}
+
+	contents := fileContents{embedded: true, keep: keepLines}
+	if oversized {
+		contents.lines, contents.omitted, err = readHeadTail(reader, keepLines)
+	} else {
+		contents.lines, err = readAllLines(reader)
+	}
+	if err != nil {
+		return fileContents{note: fmt.Sprintf("⚠️ Contents unavailable: %v", err)}
+	}
+
+	if contents.omitted > 0 {
+		contents.note = fmt.Sprintf("⚠️ Truncated: file exceeds max_file_size (%s > %s), showing the first and last %d lines",
+			formatFileSize(size), formatFileSize(limit), keepLines)
+	}
+	return contents
+}
+
+// readOutline reads a Go file as an outline without function bodies, see
+// gosrc.Outline. Binary files are not parsed.
+func readOutline(path string, unexported bool) (fileContents, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return fileContents{}, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, binarySniffLen)
+	if head, _ := reader.Peek(binarySniffLen); isBinary(head) {
+		return fileContents{}, fmt.Errorf("binary file")
+	}
+	src, err := io.ReadAll(reader)
+	if err != nil {
+		return fileContents{}, err
+	}
+	outline, err := gosrc.Outline(filepath.Base(path), src, unexported)
+	if err != nil {
+		return fileContents{}, err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(outline), "\n"), "\n")
+	return fileContents{embedded: true, lines: lines}, nil
+}
+
+// markdown formats the contents as a fenced code block, preceded by the note as a
+// blockquote
+func (c fileContents) markdown(path string) string {
+	var b strings.Builder
+	if !c.embedded {
+		fmt.Fprintf(&b, "\n> %s\n\n", c.note)
+		return b.String()
+	}
+	if c.note != "" {
+		fmt.Fprintf(&b, "\n> %s\n", c.note)
+	}
+
+	fence := codeFence(c.lines)
+	fmt.Fprintf(&b, "\n%s%s\n", fence, infoString(path))
+	b.WriteString(c.text())
+	fmt.Fprintf(&b, "%s\n\n", fence)
+	return b.String()
+}
+
+// text returns the embedded lines, with a marker in place of the lines left out
+// of truncated files
+func (c fileContents) text() string {
+	var b strings.Builder
+	for i, line := range c.lines {
+		if c.omitted > 0 && i == c.keep {
+			fmt.Fprintf(&b, "… [%d lines truncated] …\n", c.omitted)
+		}
+		fmt.Fprintf(&b, "%s\n", line)
+	}
+	return b.String()
+}
+
+// infoString returns the language hint of a code fence, the file extension unless
+// it contains characters that are not allowed after a backtick fence
+func infoString(path string) string {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if strings.ContainsAny(ext, "` \t") {
+		return ""
+	}
+	return ext
+}
+
+// isBinary reports whether the leading bytes of a file look like binary data
+func isBinary(head []byte) bool {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	// Allow a multi-byte rune to be cut off at the end of the sniffed block
+	for trim := 0; trim < utf8.UTFMax && trim <= len(head); trim++ {
+		if utf8.Valid(head[:len(head)-trim]) {
+			return false
+		}
+	}
+	return true
+}
+
+// readAllLines reads every line of r
+func readAllLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// readHeadTail reads the first and last n lines of r and returns them together with
+// the number of lines in between. Memory use is bounded by 2n lines.
+func readHeadTail(r io.Reader, n int) ([]string, int, error) {
+	var head []string
+	tail := make([]string, 0, n)
+	total := 0
+
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		total++
+		if len(head) < n {
+			head = append(head, scanner.Text())
+			continue
+		}
+		if len(tail) == n {
+			copy(tail, tail[1:])
+			tail = tail[:n-1]
+		}
+		tail = append(tail, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	omitted := total - len(head) - len(tail)
+	return append(head, tail...), omitted, nil
+}
+
+// newLineScanner returns a line scanner that tolerates long lines (minified files)
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return scanner
+}
+
+// codeFence returns a backtick fence longer than any backtick run in the lines
+func codeFence(lines []string) string {
+	longest := 0
+	for _, line := range lines {
+		run := 0
+		for _, r := range line {
+			if r == '`' {
+				run++
+				longest = max(longest, run)
+			} else {
+				run = 0
+			}
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
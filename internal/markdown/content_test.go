@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+)
+
+// outlinedFile writes a Go file and describes it as an outlined file of a
+// document with a size limit
+func outlinedFile(t *testing.T, src string, limit int64) *FileData {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "big.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{ProjectPath: root, TruncateLines: 2}
+	size := int64(len(src))
+	return &FileData{
+		Path:        "big.go",
+		Size:        size,
+		MaxFileSize: limit,
+		Oversized:   limit > 0 && size > limit,
+		Outlined:    true,
+		ctx:         context.Background(),
+		cfg:         cfg,
+	}
+}
+
+func TestOutline(t *testing.T) {
+	file := outlinedFile(t, "package big\n\n// F does f\nfunc F() {\n\tprintln(\"body\")\n}\n", 0)
+	text, err := file.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "func F()") || strings.Contains(text, "body") {
+		t.Errorf("Text() = %q, want the signature without the body", text)
+	}
+}
+
+func TestOversizedOutline(t *testing.T) {
+	var src strings.Builder
+	src.WriteString("package big\n\n")
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&src, "func F%d() {\n\tprintln(\"body\")\n}\n", i)
+	}
+
+	// Oversized files are not parsed, so they are skipped or truncated like other files
+	file := outlinedFile(t, src.String(), 64)
+	file.cfg.OversizedFiles = config.OversizedSkip
+	contents, err := file.Contents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(contents, "Contents skipped: file exceeds max_file_size") || strings.Contains(contents, "package big") {
+		t.Errorf("skip: Contents() = %q, want the skipped note", contents)
+	}
+
+	file = outlinedFile(t, src.String(), 64)
+	file.cfg.OversizedFiles = config.OversizedTruncate
+	contents, err = file.Contents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"package big", "lines truncated", "⚠️ Truncated: file exceeds max_file_size"} {
+		if !strings.Contains(contents, want) {
+			t.Errorf("truncate: Contents() is missing %q:\n%s", want, contents)
+		}
+	}
+	if strings.Contains(contents, "F10") {
+		t.Errorf("truncate: Contents() embeds the middle of the file:\n%s", contents)
+	}
+}
+
+func TestBinaryIsNotOutlined(t *testing.T) {
+	file := outlinedFile(t, "package big\x00\x01\x02", 0)
+	contents, err := file.Contents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(contents, "Binary file, contents omitted.") {
+		t.Errorf("Contents() = %q, want the binary note", contents)
+	}
+}
@@ -0,0 +1,430 @@
+package markdown
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+	"github.com/adil-chbada/extract-cli/internal/gosrc"
+	"github.com/adil-chbada/extract-cli/internal/language"
+	"github.com/adil-chbada/extract-cli/internal/scanner"
+)
+
+// Data is the data model of output templates. Sizes are in bytes; use the size
+// template function to format them.
+type Data struct {
+	Title     string    // Document title, e.g. "Project Code Files"
+	Project   string    // Project name
+	Generated time.Time // Time the document was rendered
+
+	Files  []*FileData    // Every file of the document, sorted by path
+	Groups []*GroupData   // Files grouped by directory, root directory first
+	Size   int64          // Total size of the files
+	Lines  language.Lines // Total lines of the files
+	Tokens int64          // Estimated tokens of the files
+	Branch string         // Checked out git branch of the project, if any
+
+	// Context holds the files matched by context_files, to be rendered verbatim
+	// before the other files with their Text. GoAPI lists the exported API of the
+	// Go packages in Files when context_go_api is set.
+	Context []*FileData
+	GoAPI   []gosrc.Package
+
+	// Sections split the document into named parts, e.g. the workspaces of a
+	// monorepo or the categories of a consolidated document. Empty otherwise.
+	Sections    []*SectionData
+	SectionKind string // Prefix of the section headings, e.g. "Workspace"
+
+	Largest  []*FileData // The largest_files largest files, largest first
+	ShowTree bool        // The tree config key asks for a project tree in this document
+	Errors   []ErrorData // Paths that could not be read, when report_errors is set
+
+	IncludeContents bool  // The include_contents config key is set
+	MaxFileSize     int64 // Size limit for embedded contents, 0 for no limit
+
+	cfg *config.Config
+}
+
+// SectionData is a named part of a document
+type SectionData struct {
+	Name        string
+	Heading     string // Heading text, the name prefixed with the section kind
+	Anchor      string // GitHub anchor of the heading, valid only in the default preset, see setAnchors
+	Dir         string // Directory relative to the project directory, if any
+	Files       []*FileData
+	Groups      []*GroupData
+	Size        int64
+	Lines       language.Lines
+	MaxFileSize int64 // Size limit for embedded contents of the section, 0 for no limit
+}
+
+// GroupData holds the files of a directory
+type GroupData struct {
+	Dir       string         // Directory relative to the project directory, "." for the root
+	Heading   string         // Heading text, "Root Directory" for the root
+	Anchor    string         // GitHub anchor of the heading, valid only in the default preset, see setAnchors
+	InSection bool           // The group belongs to a section
+	Link      *scanner.Link  // Set when the directory is a followed symlink
+	Files     []*FileData    // Files directly in the directory, sorted by path
+	Size      int64          // Total size of the files
+	Lines     language.Lines // Total lines of the files
+}
+
+// FileData describes a listed file
+type FileData struct {
+	Path     string         // Path relative to the project directory
+	Name     string         // Base name
+	Dir      string         // Directory relative to the project directory, "." for the root
+	Ext      string         // Extension without the dot, e.g. "go"
+	Language string         // Detected language, "Other" when unknown
+	Size     int64          // Size in bytes, -1 when the file cannot be read or is a link that is not read
+	Lines    language.Lines // Line counts; code, comment and blank only for known languages
+	Binary   bool
+
+	MaxFileSize     int64 // Size limit for the contents of the file, 0 for no limit
+	Oversized       bool  // Size exceeds MaxFileSize
+	IncludeContents bool  // The include_contents config key is set
+	Outlined        bool  // Contents are a Go outline without function bodies, see Contents
+
+	Link      *scanner.Link // Set when the file is a symlink
+	LinkState string        // "broken link", "outside project", "directory" or empty
+
+	ctx    context.Context
+	cfg    *config.Config
+	cached *fileContents // Contents read ahead of rendering, see newData
+}
+
+// ErrorData is a path that could not be read during the scan
+type ErrorData struct {
+	Path    string
+	Message string
+}
+
+// Contents returns the contents of the file as a fenced code block, or a note
+// explaining why they are missing. Files above MaxFileSize are skipped or truncated
+// according to oversized_files. Outlined files hold their Go outline; outlines are
+// parsed from the whole file, so oversized outlined files follow oversized_files too.
+func (f *FileData) Contents() (string, error) {
+	if err := f.ctx.Err(); err != nil {
+		return "", err
+	}
+	return f.read().markdown(f.Path), nil
+}
+
+// Text returns the contents of the file as plain text, truncated like Contents.
+// It is empty for binary, skipped and unreadable files.
+func (f *FileData) Text() (string, error) {
+	if err := f.ctx.Err(); err != nil {
+		return "", err
+	}
+	return f.read().text(), nil
+}
+
+// read reads the embeddable contents of the file. Go files that cannot be
+// outlined are embedded like other files, with a note.
+func (f *FileData) read() fileContents {
+	if f.cached != nil {
+		return *f.cached
+	}
+	if f.Link != nil && f.Link.ListedOnly() {
+		return fileContents{note: fmt.Sprintf("🔗 Contents not included: symlink target is %s", linkTarget(f.Link))}
+	}
+
+	path := filepath.Join(f.cfg.ProjectPath, f.Path)
+	var note string
+	// Outlines are parsed from the whole file, so oversized files are not parsed
+	if f.Outlined && !f.Oversized {
+		contents, err := readOutline(path, f.cfg.OutlineUnexported)
+		if err == nil {
+			return contents
+		}
+		note = fmt.Sprintf("⚠️ Outline unavailable: %v", err)
+	}
+
+	contents := readContents(path, f.Size, f.MaxFileSize, f.cfg.OversizedPolicy(), f.cfg.TruncateLineCount())
+	if note != "" && contents.note == "" {
+		contents.note = note
+	}
+	return contents
+}
+
+// linkTarget describes the target of a link that is listed without being read
+func linkTarget(link *scanner.Link) string {
+	switch {
+	case link.Broken:
+		return "missing"
+	case link.Escapes:
+		return "outside the project"
+	default:
+		return "a directory"
+	}
+}
+
+// Tree returns the lines of an ASCII tree of the files, shaped by the tree_depth,
+// tree_max_files and tree_sizes config keys
+func (d *Data) Tree() []string {
+	return treeLines(d.Files, d.cfg)
+}
+
+// SectionDirs reports whether any section has a directory
+func (d *Data) SectionDirs() bool {
+	for _, section := range d.Sections {
+		if section.Dir != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// newData builds the template data of a document. Files that were not inspected
+// during the scan are read once to count their lines.
+func newData(ctx context.Context, doc Document, cfg *config.Config) (*Data, error) {
+	sort.Strings(doc.Files)
+
+	data := &Data{
+		Title:           doc.Title,
+		Project:         getProjectName(cfg),
+		Generated:       time.Now(),
+		SectionKind:     doc.SectionKind,
+		ShowTree:        doc.Tree,
+		IncludeContents: cfg.IncludeContents,
+		MaxFileSize:     doc.MaxFileSize,
+		cfg:             cfg,
+	}
+	for _, pathErr := range doc.Errors {
+		data.Errors = append(data.Errors, ErrorData{Path: pathErr.Path, Message: pathErr.Err.Error()})
+	}
+
+	// Files of a section use the size limit and category of the section
+	limits := make(map[string]int64)
+	categories := make(map[string]string)
+	for _, section := range doc.Sections {
+		for _, file := range section.Files {
+			limits[file] = section.MaxFileSize
+			if section.Category != "" {
+				categories[file] = section.Category
+			}
+		}
+	}
+
+	files := make(map[string]*FileData, len(doc.Files))
+	for _, rel := range doc.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		limit, ok := limits[rel]
+		if !ok {
+			limit = doc.MaxFileSize
+		}
+		category, ok := categories[rel]
+		if !ok {
+			category = doc.Category
+		}
+		file := newFileData(ctx, rel, limit, doc, cfg)
+		file.Outlined = cfg.IsOutlined(rel, category)
+		files[rel] = file
+		data.Files = append(data.Files, file)
+		data.Size += max(file.Size, 0)
+		data.Lines.Add(file.Lines)
+	}
+
+	// Context files are read once; their headings are needed for the anchors
+	for _, rel := range doc.Context {
+		file := newFileData(ctx, rel, doc.MaxFileSize, doc, cfg)
+		contents := file.read()
+		file.cached = &contents
+		data.Context = append(data.Context, file)
+	}
+	if doc.GoAPI {
+		var sources []string
+		for _, file := range data.Files {
+			if file.Link == nil || !file.Link.ListedOnly() {
+				sources = append(sources, file.Path)
+			}
+		}
+		data.GoAPI = gosrc.API(cfg.ProjectPath, sources)
+	}
+
+	data.Tokens = language.EstimateTokens(data.Size)
+	data.Branch = doc.Branch
+	data.Groups = newGroups(doc.Files, files, doc, false)
+
+	for _, section := range doc.Sections {
+		sort.Strings(section.Files)
+		sectionData := &SectionData{
+			Name:        section.Name,
+			Heading:     sectionHeading(doc, section),
+			Dir:         section.Dir,
+			Groups:      newGroups(section.Files, files, doc, true),
+			MaxFileSize: section.MaxFileSize,
+		}
+		for _, rel := range section.Files {
+			file := files[rel]
+			sectionData.Files = append(sectionData.Files, file)
+			sectionData.Size += max(file.Size, 0)
+			sectionData.Lines.Add(file.Lines)
+		}
+		data.Sections = append(data.Sections, sectionData)
+	}
+
+	data.Largest = largestFiles(data.Files, cfg.LargestFiles)
+	data.setAnchors()
+	return data, nil
+}
+
+// newFileData describes a listed file
+func newFileData(ctx context.Context, rel string, limit int64, doc Document, cfg *config.Config) *FileData {
+	name := path.Base(rel)
+	file := &FileData{
+		Path:            rel,
+		Name:            name,
+		Dir:             path.Dir(rel),
+		Ext:             strings.TrimPrefix(filepath.Ext(name), "."),
+		Language:        language.Detect(rel),
+		Size:            -1,
+		MaxFileSize:     limit,
+		IncludeContents: cfg.IncludeContents,
+		ctx:             ctx,
+		cfg:             cfg,
+	}
+
+	if link, ok := doc.Links[rel]; ok {
+		file.Link = &link
+		switch {
+		case link.Broken:
+			file.LinkState = "broken link"
+		case link.Escapes:
+			file.LinkState = "outside project"
+		case link.Dir:
+			file.LinkState = "directory"
+		}
+		// The target of a listed link is not read
+		if link.ListedOnly() {
+			return file
+		}
+	}
+
+	info, ok := doc.Inspected[rel]
+	if !ok {
+		info, _ = language.Inspect(filepath.Join(cfg.ProjectPath, rel))
+	}
+	file.Language = info.Language
+	file.Size = getFileSize(filepath.Join(cfg.ProjectPath, rel))
+	file.Lines = info.Lines
+	file.Binary = info.Binary
+	file.Oversized = limit > 0 && file.Size > limit
+	return file
+}
+
+// newGroups groups files by directory, root directory first
+func newGroups(paths []string, files map[string]*FileData, doc Document, inSection bool) []*GroupData {
+	byDir := groupFilesByDirectory(paths)
+
+	var groups []*GroupData
+	for _, dir := range getSortedDirectories(byDir) {
+		group := &GroupData{Dir: dir, Heading: dirHeading(dir), InSection: inSection}
+		if link, ok := doc.Links[dir]; ok {
+			group.Link = &link
+		}
+		for _, rel := range byDir[dir] {
+			file := files[rel]
+			group.Files = append(group.Files, file)
+			group.Size += max(file.Size, 0)
+			group.Lines.Add(file.Lines)
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// largestFiles returns the n largest files, largest first
+func largestFiles(files []*FileData, n int) []*FileData {
+	if n <= 0 || len(files) == 0 {
+		return nil
+	}
+
+	largest := append([]*FileData(nil), files...)
+	sort.SliceStable(largest, func(i, j int) bool {
+		return largest[i].Size > largest[j].Size
+	})
+	if len(largest) > n {
+		largest = largest[:n]
+	}
+	return largest
+}
+
+// setAnchors computes the anchors of the headings of the default preset. Anchors
+// depend on every heading before them, so headings are visited in the order the
+// default preset renders them. Other templates render other headings, so their
+// anchors can differ; they link with the slug function instead.
+func (d *Data) setAnchors() {
+	slugs := newSlugger()
+	slugs.slug(d.Title)
+	if len(d.Context) > 0 || len(d.GoAPI) > 0 {
+		slugs.slug("Project Context")
+		for _, file := range d.Context {
+			slugs.slug(file.Path)
+			text, _ := file.Text()
+			for _, heading := range headings(text) {
+				slugs.slug(heading)
+			}
+		}
+		if len(d.GoAPI) > 0 {
+			slugs.slug("Go API")
+			for _, pkg := range d.GoAPI {
+				slugs.slug(pkg.Dir)
+			}
+		}
+	}
+	if len(d.Files) == 0 {
+		return
+	}
+	if d.ShowTree {
+		slugs.slug("Project Tree")
+	}
+
+	if len(d.Sections) > 0 {
+		slugs.slug("Contents")
+		if len(d.Largest) > 0 {
+			slugs.slug("Largest Files")
+		}
+		for _, section := range d.Sections {
+			section.Anchor = slugs.slug(section.Heading)
+			for _, group := range section.Groups {
+				group.Anchor = slugs.slug(group.Heading)
+			}
+		}
+		return
+	}
+
+	if len(d.Groups) > 1 {
+		slugs.slug("Table of Contents")
+	}
+	if len(d.Largest) > 0 {
+		slugs.slug("Largest Files")
+	}
+	for _, group := range d.Groups {
+		group.Anchor = slugs.slug(group.Heading)
+	}
+}
+
+// dirHeading returns the heading of a directory group
+func dirHeading(dir string) string {
+	if dir == "." {
+		return "Root Directory"
+	}
+	return dir
+}
+
+// sectionHeading returns the heading of a document section
+func sectionHeading(doc Document, section Section) string {
+	if doc.SectionKind != "" {
+		return doc.SectionKind + ": " + section.Name
+	}
+	return section.Name
+}
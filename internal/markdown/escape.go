@@ -0,0 +1,102 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// slugger generates heading anchors the way GitHub does (github-slugger): headings
+// are lower-cased, punctuation and symbols other than "-" and "_" are dropped,
+// spaces become dashes, and repeated slugs get a "-1", "-2", ... suffix.
+type slugger struct {
+	occurrences map[string]int
+}
+
+func newSlugger() *slugger {
+	return &slugger{occurrences: make(map[string]int)}
+}
+
+// slug returns the anchor of the next heading with the given text. Headings must
+// be passed in document order for duplicates to be numbered like GitHub does.
+func (s *slugger) slug(text string) string {
+	base := gfmSlug(text)
+	slug := base
+	for {
+		if _, taken := s.occurrences[slug]; !taken {
+			break
+		}
+		s.occurrences[base]++
+		slug = fmt.Sprintf("%s-%d", base, s.occurrences[base])
+	}
+	s.occurrences[slug] = 0
+	return slug
+}
+
+// gfmSlug returns the GitHub anchor of a heading without de-duplication
+func gfmSlug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(singleLine(text)) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// markdownEscaper escapes the characters that start inline formatting, links,
+// HTML or table cells
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`>`, `\>`,
+	`|`, `\|`,
+	`~`, `\~`,
+	`#`, `\#`,
+)
+
+// escapeText escapes a user-controlled string, such as a file or workspace name,
+// for use as plain text in headings, list items, links and table cells
+func escapeText(text string) string {
+	return markdownEscaper.Replace(singleLine(text))
+}
+
+// codeSpan formats text as inline code. The backtick fence is longer than any
+// backtick run in text, and padded with spaces when text starts or ends with one.
+func codeSpan(text string) string {
+	text = singleLine(text)
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return fence + " " + text + " " + fence
+	}
+	return fence + text + fence
+}
+
+// tableCode formats text as inline code in a table cell. Pipes end a cell even
+// inside inline code and are escaped.
+func tableCode(text string) string {
+	return strings.ReplaceAll(codeSpan(text), "|", `\|`)
+}
+
+// singleLine replaces line breaks, which would end a heading or list item
+func singleLine(text string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(text)
+}
@@ -0,0 +1,146 @@
+package markdown
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGfmSlug(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Root Directory", "root-directory"},
+		{"src/api", "srcapi"},
+		{"my docs/read me", "my-docsread-me"},
+		{"a  b", "a--b"},
+		{"snake_case-dir", "snake_case-dir"},
+		{"`code` and *bold*", "code-and-bold"},
+		{"[link](url) #1 | <b>", "linkurl-1--b"},
+		{"Workspace: web", "workspace-web"},
+		{"Émoji 🚀 ünïcode", "émoji--ünïcode"},
+		{"line\nbreak", "line-break"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := gfmSlug(tt.text); got != tt.want {
+			t.Errorf("gfmSlug(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSluggerDuplicates(t *testing.T) {
+	slugs := newSlugger()
+	headings := []string{"src", "src", "src-1", "src", "Src", "a b", "a-b", "a b"}
+	want := []string{"src", "src-1", "src-1-1", "src-2", "src-3", "a-b", "a-b-1", "a-b-2"}
+	for i, heading := range headings {
+		if got := slugs.slug(heading); got != want[i] {
+			t.Errorf("slug #%d (%q) = %q, want %q", i, heading, got, want[i])
+		}
+	}
+}
+
+func TestCodeSpan(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"main.go", "`main.go`"},
+		{"my file.txt", "`my file.txt`"},
+		{"a`b", "``a`b``"},
+		{"a``b`c", "```a``b`c```"},
+		{"`start", "`` `start ``"},
+		{"end`", "`` end` ``"},
+		{"two\nlines", "`two lines`"},
+	}
+	for _, tt := range tests {
+		if got := codeSpan(tt.text); got != tt.want {
+			t.Errorf("codeSpan(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	if got, want := tableCode("a|b"), "`a\\|b`"; got != want {
+		t.Errorf("tableCode(%q) = %q, want %q", "a|b", got, want)
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain name", "plain name"},
+		{"*bold* _it_", `\*bold\* \_it\_`},
+		{"[x](y)", `\[x\](y)`},
+		{"<b>|#~`", "\\<b\\>\\|\\#\\~\\`"},
+		{`back\slash`, `back\\slash`},
+		{"multi\nline", "multi line"},
+	}
+	for _, tt := range tests {
+		if got := escapeText(tt.text); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// generatedLine matches the render time of the default preset
+var generatedLine = regexp.MustCompile(`\*\*Generated:\*\* \d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}`)
+
+// TestAnchorsGolden renders the default preset for files whose names need
+// escaping and whose directories produce duplicate anchors
+func TestAnchorsGolden(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"README.md",
+		"my docs/read me.md",
+		"my-docs/notes.md",
+		"src/api/handler.go",
+		"src-api/x.go",
+		"srcapi/y.go",
+		"weird/a`b`.txt",
+		"weird/*star* [x] <y> |pipe| #hash.md",
+		"Root Directory/z.txt",
+		"docs_[v2] *draft*/a.md",
+	}
+	for _, file := range files {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+			t.Skipf("file name not supported: %v", err)
+		}
+	}
+
+	cfg := &config.Config{ProjectName: "anchors *test*", ProjectPath: root, LargestFiles: 10}
+	doc := Document{Title: "Project Code Files", Files: files}
+
+	var buf bytes.Buffer
+	if err := Render(context.Background(), &buf, doc, cfg); err != nil {
+		t.Fatal(err)
+	}
+	got := generatedLine.ReplaceAll(buf.Bytes(), []byte("**Generated:** (time)"))
+
+	golden := filepath.Join("testdata", "anchors.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run go test ./internal/markdown -update): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match:\n--- got\n%s\n--- want\n%s", golden, got, want)
+	}
+}
@@ -0,0 +1,71 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// inlineLink matches markdown links and images, whose text is kept in anchors
+var inlineLink = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+
+// headings returns the text of the ATX and setext headings of a markdown text,
+// skipping fenced code blocks. Context files are rendered verbatim, so their
+// headings take part in the anchor numbering of the document.
+func headings(text string) []string {
+	var found []string
+	fence := ""
+	previous := ""
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			previous = ""
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			previous = ""
+			continue
+		}
+
+		switch {
+		case atxHeading(trimmed) != "":
+			found = append(found, atxHeading(trimmed))
+			trimmed = ""
+		case previous != "" && trimmed != "" && strings.Trim(trimmed, "=") == "":
+			found = append(found, headingText(previous))
+			trimmed = ""
+		case previous != "" && trimmed != "" && strings.Trim(trimmed, "-") == "" && !strings.HasPrefix(previous, "- "):
+			found = append(found, headingText(previous))
+			trimmed = ""
+		}
+		previous = trimmed
+	}
+	return found
+}
+
+// atxHeading returns the text of a "# Heading" line, or "" for other lines
+func atxHeading(line string) string {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 {
+		return ""
+	}
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return ""
+	}
+	// Closing sequence, e.g. "## Heading ##"
+	rest = strings.TrimSpace(rest)
+	if trimmed := strings.TrimRight(rest, "#"); trimmed == "" || strings.HasSuffix(trimmed, " ") {
+		rest = trimmed
+	}
+	return headingText(rest)
+}
+
+// headingText returns the text of a heading as GitHub sees it for its anchor
+func headingText(text string) string {
+	return inlineLink.ReplaceAllString(strings.TrimSpace(text), "$1")
+}
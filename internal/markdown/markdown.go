@@ -1,14 +1,17 @@
 package markdown
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
-	"time"
 
+	"github.com/adil-chbada/extract-cli/internal/atomicfile"
 	"github.com/adil-chbada/extract-cli/internal/config"
+	"github.com/adil-chbada/extract-cli/internal/language"
+	"github.com/adil-chbada/extract-cli/internal/scanner"
 )
 
 // formatFileSize formats file size in human readable format
@@ -34,116 +37,118 @@ func getFileSize(filePath string) int64 {
 	return info.Size()
 }
 
-// WriteMarkdown writes a list of files to a markdown file
-func WriteMarkdown(outputPath, title string, files []string, cfg *config.Config) error {
-	file, err := os.Create(outputPath)
+// Document describes a markdown document to render
+type Document struct {
+	Title  string
+	Files  []string            // Paths relative to the project directory
+	Errors []scanner.PathError // Rendered as a "Scan Errors" section when not empty
+	Links  map[string]scanner.Link
+	// Inspected holds the language and line counts of the files, inspected once per
+	// scan. Files missing from it are read while rendering.
+	Inspected map[string]language.File
+	Branch    string // Checked out git branch of the project, if any
+
+	MaxFileSize int64  // Size limit for embedded contents, 0 for no limit
+	Category    string // Category of the files for outline_categories, empty when mixed
+	Tree        bool   // Start with a tree of the files, see config tree_* keys
+
+	// Context files are project documentation rendered verbatim before the files,
+	// see config context_files. GoAPI adds an API listing of the Go packages in Files.
+	Context []string
+	GoAPI   bool
+
+	// Sections split the files into named parts, e.g. monorepo workspaces or the
+	// categories of a consolidated document. Files must hold the files of all sections.
+	Sections    []Section
+	SectionKind string // Prefix of the section headings, e.g. "Workspace"
+}
+
+// Section is a named part of a document rendered under its own heading
+type Section struct {
+	Name  string
+	Dir   string   // Directory of the section relative to the project directory, if any
+	Files []string // Paths relative to the project directory
+
+	MaxFileSize int64  // Size limit for embedded contents of the section, 0 for no limit
+	Category    string // Category of the files, when it differs from the document
+}
+
+// WriteMarkdown writes a document to a markdown file. The file is written to a
+// temporary path first and only replaces outputPath once rendering succeeded.
+func WriteMarkdown(ctx context.Context, outputPath string, doc Document, cfg *config.Config) error {
+	file, err := atomicfile.Create(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create markdown file: %w", err)
 	}
-	defer file.Close()
+	defer file.Abort()
 
-	// Sort files for consistent output
-	sort.Strings(files)
+	if err := Render(ctx, file, doc, cfg); err != nil {
+		return err
+	}
 
-	// Write header
-	fmt.Fprintf(file, "# %s\n\n", title)
+	return file.Commit()
+}
 
-	// Calculate total size
-	totalSize := int64(0)
-	for _, filePath := range files {
-		fileSize := getFileSize(filepath.Join(cfg.ProjectPath, filePath))
-		if fileSize >= 0 {
-			totalSize += fileSize
-		}
+// Render writes the markdown document to w using the output template selected by
+// the template config key, between the configured preamble and postamble
+func Render(ctx context.Context, w io.Writer, doc Document, cfg *config.Config) error {
+	tmpl, err := loadTemplate(cfg)
+	if err != nil {
+		return err
 	}
-	
-	// Write metadata
-	fmt.Fprintf(file, "**Project:** %s  \n", getProjectName(cfg))
-	fmt.Fprintf(file, "**Generated:** %s  \n", time.Now().Format("2006-01-02 15:04:05"))
-	fmt.Fprintf(file, "**Total Files:** %d  \n", len(files))
-	fmt.Fprintf(file, "**Total Size:** %s  \n\n", formatFileSize(totalSize))
-
-	if len(files) == 0 {
-		fmt.Fprintf(file, "*No files found matching the criteria.*\n")
-		return nil
-	}
-
-	// Group files by directory for better organization
-	groups := groupFilesByDirectory(files)
-
-	// Write table of contents if there are multiple groups
-	if len(groups) > 1 {
-		fmt.Fprintf(file, "## Table of Contents\n\n")
-		for _, dir := range getSortedDirectories(groups) {
-			anchor := strings.ReplaceAll(strings.ToLower(dir), "/", "-")
-			if dir == "." {
-				fmt.Fprintf(file, "- [Root Directory](#root-directory)\n")
-			} else {
-				fmt.Fprintf(file, "- [%s](#%s)\n", dir, anchor)
-			}
-		}
-		fmt.Fprintf(file, "\n")
-	}
-
-	// Write files grouped by directory
-	for _, dir := range getSortedDirectories(groups) {
-		dirFiles := groups[dir]
-		
-		// Calculate directory size
-		dirSize := int64(0)
-		for _, filePath := range dirFiles {
-			fileSize := getFileSize(filepath.Join(cfg.ProjectPath, filePath))
-			if fileSize >= 0 {
-				dirSize += fileSize
-			}
-		}
 
-		if dir == "." {
-			fmt.Fprintf(file, "## Root Directory\n\n")
-		} else {
-			fmt.Fprintf(file, "## %s\n\n", dir)
-		}
+	data, err := newData(ctx, doc, cfg)
+	if err != nil {
+		return err
+	}
 
-		fmt.Fprintf(file, "**Files in this directory:** %d  \n", len(dirFiles))
-		fmt.Fprintf(file, "**Directory size:** %s\n\n", formatFileSize(dirSize))
-
-		for _, filePath := range dirFiles {
-			filename := filepath.Base(filePath)
-			
-			// Get file size
-			fileSize := getFileSize(filepath.Join(cfg.ProjectPath, filePath))
-			sizeStr := "unknown"
-			if fileSize >= 0 {
-				sizeStr = formatFileSize(fileSize)
-			}
-			
-			fmt.Fprintf(file, "- `%s` **(%s)**", filename, sizeStr)
-
-			// Add file extension info
-			ext := filepath.Ext(filename)
-			if ext != "" {
-				fmt.Fprintf(file, " *(%s)*", strings.TrimPrefix(ext, "."))
-			}
-
-			// Add relative path if different from filename
-			if filePath != filename {
-				fmt.Fprintf(file, "  \n  📁 `%s`", filePath)
-			}
-
-			fmt.Fprintf(file, "\n")
+	preamble, postamble, err := framing(cfg, data)
+	if err != nil {
+		return err
+	}
+
+	out := &errWriter{w: w}
+	if preamble != "" {
+		fmt.Fprintf(out, "%s\n\n", preamble)
+	}
+	if err := tmpl.Execute(out, data); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
 		}
-		fmt.Fprintf(file, "\n")
+		if out.err != nil {
+			return out.err
+		}
+		return fmt.Errorf("failed to render template %s: %w", cfg.TemplateName(), err)
+	}
+	if postamble != "" {
+		fmt.Fprintf(out, "\n%s\n", postamble)
+	}
+	return out.err
+}
+
+// formatLines formats a line count with its code, comment and blank breakdown
+func formatLines(lines language.Lines) string {
+	if !lines.Classified() {
+		return fmt.Sprintf("%d", lines.Total)
 	}
+	return fmt.Sprintf("%d (%d code, %d comment, %d blank)", lines.Total, lines.Code, lines.Comment, lines.Blank)
+}
 
-	// Write summary footer
-	fmt.Fprintf(file, "---\n\n")
-	fmt.Fprintf(file, "**Summary:**\n")
-	fmt.Fprintf(file, "- Total files listed: %d\n", len(files))
-	fmt.Fprintf(file, "- Total size: %s\n", formatFileSize(totalSize))
-	fmt.Fprintf(file, "- Directories covered: %d\n", len(groups))
-	fmt.Fprintf(file, "- Generated by extract-cli\n")
+// errWriter remembers the first write error so rendering can stop checking every call
+type errWriter struct {
+	w   io.Writer
+	err error
+}
 
-	return nil
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	if err != nil {
+		e.err = fmt.Errorf("failed to write markdown: %w", err)
+	}
+	return n, err
 }
 
 // groupFilesByDirectory groups files by their directory
@@ -0,0 +1,62 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+)
+
+// unknownBranch is the value of ${branch} outside a git repository
+const unknownBranch = "unknown"
+
+// variablePattern matches ${name} variables of preambles and postambles
+var variablePattern = regexp.MustCompile(`\$\{([a-z_]+)\}`)
+
+// framing returns the preamble and postamble of a document with their variables
+// expanded
+func framing(cfg *config.Config, data *Data) (string, string, error) {
+	preamble, err := cfg.PreambleText()
+	if err != nil {
+		return "", "", err
+	}
+	postamble, err := cfg.PostambleText()
+	if err != nil {
+		return "", "", err
+	}
+	return expandVariables(preamble, data), expandVariables(postamble, data), nil
+}
+
+// expandVariables replaces the ${name} variables of text with values of the
+// document. Unknown variables are left as they are.
+func expandVariables(text string, data *Data) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+
+	// Outside a git repository the branch is unknown; an empty value would leave
+	// a gap in sentences like "on ${branch} (...)"
+	branch := data.Branch
+	if branch == "" {
+		branch = unknownBranch
+	}
+
+	values := map[string]string{
+		"project":   data.Project,
+		"title":     data.Title,
+		"files":     strconv.Itoa(len(data.Files)),
+		"size":      formatFileSize(data.Size),
+		"lines":     strconv.Itoa(data.Lines.Total),
+		"tokens":    strconv.FormatInt(data.Tokens, 10),
+		"branch":    branch,
+		"generated": data.Generated.Format("2006-01-02 15:04:05"),
+	}
+	return variablePattern.ReplaceAllStringFunc(text, func(variable string) string {
+		if value, ok := values[variable[2:len(variable)-1]]; ok {
+			return value
+		}
+		return variable
+	})
+}
@@ -0,0 +1,30 @@
+package markdown
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandVariables(t *testing.T) {
+	data := &Data{
+		Project:   "app",
+		Title:     "Project Code Files",
+		Size:      2048,
+		Tokens:    512,
+		Branch:    "main",
+		Generated: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+	}
+	data.Lines.Total = 42
+
+	text := "  ${project} on ${branch} (${size}, ${lines} lines, ${tokens} tokens) at ${generated} ${missing}\n"
+	want := "app on main (2.0 KB, 42 lines, 512 tokens) at 2024-05-01 12:30:00 ${missing}"
+	if got := expandVariables(text, data); got != want {
+		t.Errorf("expandVariables() = %q, want %q", got, want)
+	}
+
+	// Outside a git repository the branch has a placeholder
+	data.Branch = ""
+	if got, want := expandVariables("on ${branch} (", data), "on unknown ("; got != want {
+		t.Errorf("expandVariables() without a branch = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package markdown
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+)
+
+// presets holds the built-in output templates, one <name>.tmpl per preset
+//
+//go:embed templates/*.tmpl
+var presets embed.FS
+
+// templateFuncs are the functions available to output templates next to the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	"size":      formatFileSize, // 1536 → "1.5 KB"
+	"lines":     formatLines,    // Line counts with their code, comment and blank breakdown
+	"plural":    plural,         // plural 2 "file" → "files"
+	"md":        escapeText,     // Escape a name for markdown text
+	"code":      codeSpan,       // Inline code that survives backticks in the text
+	"tablecode": tableCode,      // Inline code inside a table cell
+	"slug":      gfmSlug,        // GitHub anchor of a heading, without de-duplication
+	"fence":     fenceFor,       // Backtick fence longer than any backtick run in the text
+	"join":      strings.Join,
+	"add":       func(a, b int) int { return a + b },
+}
+
+// PresetSource returns the text of a built-in output template
+func PresetSource(name string) (string, error) {
+	if !config.IsTemplatePreset(name) {
+		return "", fmt.Errorf("unknown template preset %q (expected %s)", name, strings.Join(config.TemplatePresets, ", "))
+	}
+	text, err := presets.ReadFile("templates/" + name + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("failed to read template preset: %w", err)
+	}
+	return string(text), nil
+}
+
+// loadTemplate parses the output template selected by the config
+func loadTemplate(cfg *config.Config) (*template.Template, error) {
+	name := cfg.TemplateName()
+
+	var text string
+	if config.IsTemplatePreset(name) {
+		source, err := PresetSource(name)
+		if err != nil {
+			return nil, err
+		}
+		text = source
+	} else {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		text = string(data)
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// fenceFor returns a code fence for text given as a string or as lines
+func fenceFor(text any) (string, error) {
+	switch v := text.(type) {
+	case string:
+		return codeFence(strings.Split(v, "\n")), nil
+	case []string:
+		return codeFence(v), nil
+	default:
+		return "", fmt.Errorf("fence: unsupported argument type %T", text)
+	}
+}
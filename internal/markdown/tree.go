@@ -0,0 +1,137 @@
+package markdown
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+)
+
+// treeNode is a directory of the project tree
+type treeNode struct {
+	name  string
+	dirs  map[string]*treeNode
+	files []treeFile
+	count int   // Files in the directory and its subdirectories
+	size  int64 // Size of those files
+}
+
+// treeFile is a file of the project tree
+type treeFile struct {
+	name string
+	size int64
+}
+
+// buildTree arranges files by directory
+func buildTree(name string, files []*FileData) *treeNode {
+	root := &treeNode{name: name, dirs: make(map[string]*treeNode)}
+	for _, file := range files {
+		size := max(file.Size, 0)
+		parts := strings.Split(filepath.ToSlash(file.Path), "/")
+
+		node := root
+		node.count++
+		node.size += size
+		for _, dir := range parts[:len(parts)-1] {
+			child, ok := node.dirs[dir]
+			if !ok {
+				child = &treeNode{name: dir, dirs: make(map[string]*treeNode)}
+				node.dirs[dir] = child
+			}
+			node = child
+			node.count++
+			node.size += size
+		}
+		node.files = append(node.files, treeFile{name: parts[len(parts)-1], size: size})
+	}
+	return root
+}
+
+// treeLines returns an ASCII tree of files, similar to `tree --dirsfirst`.
+// Directories below tree_depth are collapsed to their file count, and files past
+// tree_max_files in a directory are folded into a "… N more files" line.
+func treeLines(files []*FileData, cfg *config.Config) []string {
+	root := buildTree(getProjectName(cfg), files)
+
+	lines := []string{root.name + "/" + treeSize(cfg, root.size)}
+	return root.appendLines(lines, "", 1, cfg)
+}
+
+// appendLines appends the entries of a directory at the given depth
+func (n *treeNode) appendLines(lines []string, prefix string, depth int, cfg *config.Config) []string {
+	dirs := make([]string, 0, len(n.dirs))
+	for name := range n.dirs {
+		dirs = append(dirs, name)
+	}
+	sort.Strings(dirs)
+
+	files := n.files
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	var folded []treeFile
+	if limit := cfg.TreeMaxFileCount(); limit > 0 && len(files) > limit {
+		files, folded = files[:limit], files[limit:]
+	}
+
+	entries := len(dirs) + len(files)
+	if len(folded) > 0 {
+		entries++
+	}
+	entry := 0
+	branch := func() (string, string) {
+		entry++
+		if entry == entries {
+			return prefix + "└── ", prefix + "    "
+		}
+		return prefix + "├── ", prefix + "│   "
+	}
+
+	for _, name := range dirs {
+		dir := n.dirs[name]
+		line, childPrefix := branch()
+		if cfg.TreeDepth > 0 && depth >= cfg.TreeDepth {
+			lines = append(lines, line+dir.name+"/ … "+fileCount(dir.count)+treeSize(cfg, dir.size))
+			continue
+		}
+		lines = append(lines, line+dir.name+"/"+treeSize(cfg, dir.size))
+		lines = dir.appendLines(lines, childPrefix, depth+1, cfg)
+	}
+
+	for _, file := range files {
+		line, _ := branch()
+		lines = append(lines, line+file.name+treeSize(cfg, file.size))
+	}
+
+	if len(folded) > 0 {
+		var size int64
+		for _, file := range folded {
+			size += file.size
+		}
+		line, _ := branch()
+		lines = append(lines, fmt.Sprintf("%s… %d more %s%s", line, len(folded), plural(len(folded), "file"), treeSize(cfg, size)))
+	}
+
+	return lines
+}
+
+// treeSize formats a size for the tree, or "" when sizes are not shown
+func treeSize(cfg *config.Config, size int64) string {
+	if !cfg.TreeSizes {
+		return ""
+	}
+	return " (" + formatFileSize(size) + ")"
+}
+
+// fileCount formats a number of files
+func fileCount(n int) string {
+	return fmt.Sprintf("%d %s", n, plural(n, "file"))
+}
+
+// plural returns word with an "s" unless n is 1
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
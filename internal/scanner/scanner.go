@@ -1,6 +1,8 @@
 package scanner
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -16,90 +18,431 @@ type ScanResult struct {
 	Code     []string
 	Data     []string
 	Locals   []string
+	Context  []string // Documentation matched by context_files, kept out of the other categories
 	Total    int
 	Excluded int
+	Errors   []PathError     // Paths that could not be read; empty in strict mode
+	Links    map[string]Link // Symbolic links encountered, keyed by relative path
+	// SkippedLinks holds the links that were not followed because their target is
+	// missing or outside the project
+	SkippedLinks []string
+	// ExcludedBy counts the excluded paths per rule: an exclude pattern, a
+	// .gitignore file or one of the Rule* names
+	ExcludedBy map[string]int
 }
 
-// Scan scans the project directory and categorizes files
-func Scan(projectPath string, cfg *config.Config) (*ScanResult, error) {
+// Exclusion rules that are not patterns
+const (
+	RuleInclude        = "include_patterns"  // Not matched by any include pattern
+	RuleSymlink        = "symlinks: skip"    // Symbolic link skipped by policy
+	RuleSymlinkCycle   = "symlink cycle"     // Link to a directory already being walked
+	RuleUnfollowedLink = "unfollowable link" // Broken link or link outside the project
+)
+
+// Link describes a symbolic link found during the scan
+type Link struct {
+	Target  string // Link target as stored in the link
+	Dir     bool   // Target is a directory
+	Escapes bool   // Target resolves outside the project directory
+	Broken  bool   // Target does not exist
+	Cycle   bool   // Following the link would revisit a directory already being walked
+}
+
+// ListedOnly reports whether a listed link must not be read through: its target
+// is missing, outside the project or a directory
+func (l Link) ListedOnly() bool {
+	return l.Broken || l.Escapes || l.Dir
+}
+
+// PathError records a path that could not be read during the scan
+type PathError struct {
+	Path string // Path relative to the project directory
+	Err  error
+}
+
+func (e PathError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e PathError) Unwrap() error {
+	return e.Err
+}
+
+// Progress is a snapshot of a running scan
+type Progress struct {
+	Walked  int    // Files and directories visited
+	Matched int    // Files listed in a category
+	Bytes   int64  // Total size of the matched files
+	Path    string // Path visited last, relative to the project directory
+}
+
+// Option configures a scan
+type Option func(*walker)
+
+// WithProgress calls fn after every visited path. fn runs on the scanning
+// goroutine and should return quickly.
+func WithProgress(fn func(Progress)) Option {
+	return func(w *walker) {
+		w.progress = fn
+	}
+}
+
+// Scan scans the project directory and categorizes files. The walk stops as soon
+// as ctx is cancelled. Unreadable paths are collected in ScanResult.Errors and
+// skipped, unless cfg.Strict is set, in which case the first error aborts the scan.
+// Symbolic links are handled according to cfg.Symlinks.
+func Scan(ctx context.Context, projectPath string, cfg *config.Config, opts ...Option) (*ScanResult, error) {
+	return ScanDir(ctx, projectPath, "", cfg, opts...)
+}
+
+// ScanDir scans dir, a directory relative to the project directory, like Scan.
+// Paths in the result stay relative to the project directory, and the .gitignore
+// files of both the project and dir apply.
+func ScanDir(ctx context.Context, projectPath, dir string, cfg *config.Config, opts ...Option) (*ScanResult, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
 	result := &ScanResult{
-		Code:   []string{},
-		Data:   []string{},
-		Locals: []string{},
+		Code:    []string{},
+		Data:    []string{},
+		Locals:  []string{},
+		Context: []string{},
+		Links:   map[string]Link{},
+
+		ExcludedBy: map[string]int{},
 	}
 
-	// Load .gitignore patterns
-	ignorer, err := loadGitignore(projectPath)
+	// Symlink targets are compared against the resolved project path
+	realRoot, err := filepath.EvalSymlinks(projectPath)
 	if err != nil {
-		// .gitignore is optional, continue without it
-		ignorer = nil
+		return nil, fmt.Errorf("failed to resolve project path: %w", err)
 	}
 
-	err = filepath.WalkDir(projectPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+	dir = strings.Trim(filepath.ToSlash(filepath.Clean(dir)), "/")
+	if dir == "." {
+		dir = ""
+	}
+	start := filepath.Join(projectPath, filepath.FromSlash(dir))
+
+	w := &walker{
+		ctx:         ctx,
+		cfg:         cfg,
+		projectPath: projectPath,
+		root:        start,
+		realRoot:    realRoot,
+		result:      result,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	// Load .gitignore patterns; they are optional
+	if ignorer, err := loadGitignore(projectPath); err == nil {
+		w.ignorers = append(w.ignorers, scopedIgnore{ignorer: ignorer})
+	}
+	if dir != "" {
+		if ignorer, err := loadGitignore(start); err == nil {
+			w.ignorers = append(w.ignorers, scopedIgnore{dir: dir, ignorer: ignorer})
 		}
+	}
 
-		// Skip the root directory itself
-		if path == projectPath {
-			return nil
+	if err := w.walk(start, dir); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
 		}
+		return nil, fmt.Errorf("failed to scan directory: %w", err)
+	}
+
+	return result, nil
+}
+
+// scopedIgnore holds the .gitignore patterns of a directory
+type scopedIgnore struct {
+	dir     string // Directory of the .gitignore, relative to the project; empty for the project
+	ignorer *ignore.GitIgnore
+}
+
+// name returns the path of the .gitignore file
+func (s scopedIgnore) name() string {
+	if s.dir == "" {
+		return ".gitignore"
+	}
+	return s.dir + "/.gitignore"
+}
+
+// matches reports whether the patterns ignore a project-relative path
+func (s scopedIgnore) matches(relPath string) bool {
+	if s.dir == "" {
+		return s.ignorer.MatchesPath(relPath)
+	}
+	rel, ok := strings.CutPrefix(relPath, s.dir+"/")
+	return ok && s.ignorer.MatchesPath(rel)
+}
+
+// walker holds the state of a single scan
+type walker struct {
+	ctx         context.Context
+	cfg         *config.Config
+	projectPath string
+	root        string // Directory the scan starts in
+	realRoot    string // Resolved project directory
+	ignorers    []scopedIgnore
+	result      *ScanResult
+	progress    func(Progress)
+	status      Progress
+	// following holds the resolved directories of the symlinks currently being
+	// followed together with the directories containing them, for cycle detection
+	following []string
+}
+
+// walk walks dir and records its files. relPrefix is the project-relative path
+// dir is reached through; it is empty for the project directory itself.
+func (w *walker) walk(dir, relPrefix string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		relPath := joinRel(relPrefix, relativePath(dir, path))
 
-		// Get relative path
-		relPath, err := filepath.Rel(projectPath, path)
 		if err != nil {
-			return fmt.Errorf("failed to get relative path: %w", err)
+			// The scanned directory itself must be readable
+			if w.cfg.Strict || path == w.root {
+				return err
+			}
+			w.addError(relPath, unwrapPathError(err))
+			// WalkDir does not descend into a directory it failed to read
+			return nil
 		}
 
-		// Convert to forward slashes for consistent pattern matching
-		relPath = filepath.ToSlash(relPath)
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+
+		// Skip the walked directory itself
+		if path == dir {
+			return nil
+		}
 
-		result.Total++
+		w.result.Total++
+		defer w.report(relPath)
 
 		// Skip directories (we only process files)
 		if d.IsDir() {
 			return nil
 		}
 
-		// Check if file should be ignored by .gitignore
-		if ignorer != nil && ignorer.MatchesPath(relPath) {
-			result.Excluded++
-			return nil
+		if d.Type()&fs.ModeSymlink != 0 {
+			return w.visitSymlink(path, relPath)
 		}
 
-		// Check if file should be excluded by config patterns
-		if cfg.IsExcluded(relPath) {
-			result.Excluded++
-			return nil
+		w.visitFile(relPath)
+		return nil
+	})
+}
+
+// visitFile applies the exclusion rules to a file and categorizes it
+func (w *walker) visitFile(relPath string) {
+	cfg, result := w.cfg, w.result
+
+	// Check if file should be ignored by .gitignore
+	for _, ignorer := range w.ignorers {
+		if ignorer.matches(relPath) {
+			w.exclude(ignorer.name())
+			return
 		}
+	}
 
-		// Categorize the file
-		switch {
-		case cfg.IsDataFile(relPath):
-			result.Data = append(result.Data, relPath)
-		case cfg.IsLocalFile(relPath):
-			// Check if it's a main local file (should go to code)
-			if cfg.IsMainLocalFile(relPath) {
-				result.Code = append(result.Code, relPath)
-			} else {
-				result.Locals = append(result.Locals, relPath)
-			}
-		default:
+	// Check if file should be excluded by config patterns
+	if pattern, ok := cfg.ExcludingPattern(relPath); ok {
+		w.exclude(pattern)
+		return
+	}
+	if !cfg.IsIncluded(relPath) {
+		w.exclude(RuleInclude)
+		return
+	}
+
+	w.matched(relPath)
+
+	// Categorize the file
+	switch {
+	case cfg.IsContextFile(relPath):
+		result.Context = append(result.Context, relPath)
+	case cfg.IsDataFile(relPath):
+		result.Data = append(result.Data, relPath)
+	case cfg.IsLocalFile(relPath):
+		// Check if it's a main local file (should go to code)
+		if cfg.IsMainLocalFile(relPath) {
 			result.Code = append(result.Code, relPath)
+		} else {
+			result.Locals = append(result.Locals, relPath)
 		}
+	default:
+		result.Code = append(result.Code, relPath)
+	}
+}
 
+// visitSymlink handles a symbolic link according to the symlink policy
+func (w *walker) visitSymlink(path, relPath string) error {
+	policy := w.cfg.SymlinkPolicy()
+	if policy == config.SymlinksSkip {
+		w.exclude(RuleSymlink)
 		return nil
-	})
+	}
+
+	link := Link{}
+	if target, err := os.Readlink(path); err == nil {
+		link.Target = filepath.ToSlash(target)
+	}
 
+	resolved, err := filepath.EvalSymlinks(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan directory: %w", err)
+		// Broken links are listed as before but cannot be followed
+		link.Broken = true
+		if policy == config.SymlinksFollow {
+			w.skipLink(relPath, link)
+			return nil
+		}
+		w.addLink(relPath, link)
+		return nil
 	}
 
-	return result, nil
+	rel, err := filepath.Rel(w.realRoot, resolved)
+	link.Escapes = err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		if w.cfg.Strict {
+			return fmt.Errorf("%s: %w", relPath, err)
+		}
+		w.result.Links[relPath] = link
+		w.addError(relPath, err)
+		return nil
+	}
+	link.Dir = info.IsDir()
+
+	if policy == config.SymlinksList {
+		w.addLink(relPath, link)
+		return nil
+	}
+
+	// Never follow links out of the project directory
+	if link.Escapes {
+		w.skipLink(relPath, link)
+		return nil
+	}
+
+	if !link.Dir {
+		w.result.Links[relPath] = link
+		w.visitFile(relPath)
+		return nil
+	}
+
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		parent = filepath.Dir(path)
+	}
+	if w.isCycle(resolved, parent) {
+		link.Cycle = true
+		w.result.Links[relPath] = link
+		w.exclude(RuleSymlinkCycle)
+		return nil
+	}
+
+	w.result.Links[relPath] = link
+	w.following = append(w.following, parent, resolved)
+	defer func() { w.following = w.following[:len(w.following)-2] }()
+
+	return w.walk(resolved, relPath)
+}
+
+// matched updates the progress counters for a listed file
+func (w *walker) matched(relPath string) {
+	if w.progress == nil {
+		return
+	}
+	w.status.Matched++
+	if link, ok := w.result.Links[relPath]; ok && link.ListedOnly() {
+		return
+	}
+	if info, err := os.Stat(filepath.Join(w.projectPath, filepath.FromSlash(relPath))); err == nil {
+		w.status.Bytes += info.Size()
+	}
+}
+
+// report sends a progress event for a visited path
+func (w *walker) report(relPath string) {
+	if w.progress == nil {
+		return
+	}
+	w.status.Walked++
+	w.status.Path = relPath
+	w.progress(w.status)
+}
+
+// isCycle reports whether following a link to target from the directory parent
+// would revisit a directory that is already being walked
+func (w *walker) isCycle(target, parent string) bool {
+	for _, dir := range append([]string{parent}, w.following...) {
+		if dir == target || strings.HasPrefix(dir, target+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// addLink records a listed (not followed) link as a file entry. Its target is
+// never read: links to directories are listed like files.
+func (w *walker) addLink(relPath string, link Link) {
+	w.result.Links[relPath] = link
+	w.visitFile(relPath)
+}
+
+// skipLink records a broken link or a link out of the project that is not
+// followed. It is excluded rather than reported as an unreadable path.
+func (w *walker) skipLink(relPath string, link Link) {
+	w.result.Links[relPath] = link
+	w.result.SkippedLinks = append(w.result.SkippedLinks, relPath)
+	w.exclude(RuleUnfollowedLink)
+}
+
+// exclude counts a path excluded by rule
+func (w *walker) exclude(rule string) {
+	w.result.Excluded++
+	w.result.ExcludedBy[rule]++
+}
+
+// addError records a path that could not be processed
+func (w *walker) addError(relPath string, err error) {
+	w.result.Errors = append(w.result.Errors, PathError{Path: relPath, Err: err})
+}
+
+// joinRel joins a project-relative prefix and a relative path
+func joinRel(prefix, rel string) string {
+	switch {
+	case rel == ".":
+		return prefix
+	case prefix == "":
+		return rel
+	default:
+		return prefix + "/" + rel
+	}
+}
+
+// relativePath returns path relative to the project directory with forward slashes
+func relativePath(projectPath, path string) string {
+	relPath, err := filepath.Rel(projectPath, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// unwrapPathError strips the *fs.PathError wrapper since PathError already carries the path
+func unwrapPathError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
 }
 
 // loadGitignore loads .gitignore patterns from the project directory
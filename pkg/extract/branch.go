@@ -0,0 +1,72 @@
+package extract
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitBranch returns the checked out branch of the git repository containing dir,
+// the abbreviated commit for a detached HEAD, or "" outside of a repository
+func gitBranch(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		if gitDir, ok := findGitDir(dir); ok {
+			head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+			if err != nil {
+				return ""
+			}
+			ref := strings.TrimSpace(string(head))
+			if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+				return branch
+			}
+			if len(ref) > 7 {
+				return ref[:7]
+			}
+			return ref
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// findGitDir returns the git directory of a repository rooted at dir. Worktrees
+// and submodules have a .git file pointing to their git directory.
+func findGitDir(dir string) (string, bool) {
+	path := filepath.Join(dir, ".git")
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	if info.IsDir() {
+		return path, true
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return "", false
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "gitdir: ")
+	if !ok {
+		return "", false
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	return gitDir, true
+}
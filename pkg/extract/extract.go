@@ -0,0 +1,664 @@
+// Package extract scans a project directory, categorizes its files into code,
+// data and local files, and renders them as markdown documents. It is the
+// library behind the extract-cli commands.
+//
+//	cfg, err := extract.LoadConfig("extract.config.yml")
+//	if err != nil {
+//		return err
+//	}
+//	ex, err := extract.New(cfg)
+//	if err != nil {
+//		return err
+//	}
+//	summary, err := ex.Generate(ctx, "./docs")
+package extract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adil-chbada/extract-cli/internal/atomicfile"
+	"github.com/adil-chbada/extract-cli/internal/config"
+	"github.com/adil-chbada/extract-cli/internal/language"
+	"github.com/adil-chbada/extract-cli/internal/markdown"
+	"github.com/adil-chbada/extract-cli/internal/scanner"
+)
+
+// Config is the extraction configuration, usually loaded from extract.config.yml
+type Config = config.Config
+
+// Result holds the categorized files of a scan, relative to the project path
+type Result = scanner.ScanResult
+
+// PathError records a path that could not be read during a scan
+type PathError = scanner.PathError
+
+// Category identifies one of the generated documents
+type Category string
+
+const (
+	CategoryCode   Category = "code"
+	CategoryData   Category = "data"
+	CategoryLocals Category = "locals"
+	// CategoryAll is the consolidated document holding every category
+	CategoryAll Category = "all"
+	// CategoryContext holds the documentation matched by context_files. It has no
+	// document of its own; its files are rendered first in the code document.
+	CategoryContext Category = "context"
+)
+
+// categoryInfo describes the output document of a category
+type categoryInfo struct {
+	category Category
+	filename string
+	title    string
+}
+
+// categories lists the generated documents in output order
+var categories = []categoryInfo{
+	{CategoryCode, "project-code.md", "Project Code Files"},
+	{CategoryData, "project-data.md", "Project Data Files"},
+	{CategoryLocals, "project-locals.md", "Project Local Files"},
+}
+
+// consolidated describes the single document written in the consolidated format
+var consolidated = categoryInfo{CategoryAll, "project.md", "Project Files"}
+
+// Categories returns all categories in output order
+func Categories() []Category {
+	list := make([]Category, 0, len(categories))
+	for _, info := range categories {
+		list = append(list, info.category)
+	}
+	return list
+}
+
+// countedCategories returns the categories counted in summaries and statistics:
+// the document categories followed by the context files
+func countedCategories() []Category {
+	return append(Categories(), CategoryContext)
+}
+
+// LoadConfig loads a config file and applies the defaults and common exclusions
+func LoadConfig(path string) (*Config, error) {
+	return config.LoadConfig(path)
+}
+
+// Option configures an Extractor
+type Option func(*Extractor)
+
+// WithProjectPath overrides the directory that is scanned
+func WithProjectPath(path string) Option {
+	return func(e *Extractor) {
+		e.projectPath = path
+	}
+}
+
+// WithPaths limits the scan to the given directories of the project. Relative paths
+// are resolved against the working directory.
+func WithPaths(paths ...string) Option {
+	return func(e *Extractor) {
+		e.paths = append(e.paths, paths...)
+	}
+}
+
+// Progress stages
+const (
+	StageScan  = "scan"  // Walking the project directory
+	StageWrite = "write" // Rendering the documents
+)
+
+// Progress reports the state of a running Scan or Generate
+type Progress struct {
+	Stage   string
+	Walked  int    // Files and directories visited
+	Matched int    // Files listed in a category
+	Bytes   int64  // Total size of the matched files
+	Path    string // Path visited last while scanning, or the document being written
+	// Documents written so far and the number of documents to write
+	Written, Documents int
+}
+
+// WithProgress calls fn with progress events while scanning and writing. fn runs on
+// the calling goroutine and should return quickly.
+func WithProgress(fn func(Progress)) Option {
+	return func(e *Extractor) {
+		e.progress = fn
+	}
+}
+
+// Extractor scans a project and renders its files
+type Extractor struct {
+	cfg         *Config
+	projectPath string
+	paths       []string // Scanned directories relative to the project path; empty for all
+	progress    func(Progress)
+	status      Progress // Progress of the previous scans of a run
+}
+
+// New creates an Extractor for the given config
+func New(cfg *Config, opts ...Option) (*Extractor, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+
+	e := &Extractor{cfg: cfg, projectPath: cfg.ProjectPath}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if e.projectPath != cfg.ProjectPath {
+		abs, err := filepath.Abs(e.projectPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve project path: %w", err)
+		}
+		// Sizes and file paths in the output are resolved against the config
+		e.cfg = cfg.Clone()
+		e.cfg.ProjectPath = abs
+		e.projectPath = abs
+	}
+
+	if len(e.paths) > 0 {
+		if len(e.cfg.Workspaces) > 0 {
+			return nil, fmt.Errorf("paths cannot be combined with workspaces")
+		}
+		paths, err := resolvePaths(e.projectPath, e.paths)
+		if err != nil {
+			return nil, err
+		}
+		e.paths = paths
+	}
+
+	return e, nil
+}
+
+// resolvePaths converts paths to directories relative to the project path. Paths
+// nested in another path are dropped so no file is scanned twice.
+func resolvePaths(projectPath string, paths []string) ([]string, error) {
+	var dirs []string
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(projectPath, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside the project directory %s", path, projectPath)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", path)
+		}
+		dirs = append(dirs, filepath.ToSlash(rel))
+	}
+
+	sort.Strings(dirs)
+	var resolved []string
+	for _, dir := range dirs {
+		if len(resolved) > 0 && isWithin(dir, resolved[len(resolved)-1]) {
+			continue
+		}
+		resolved = append(resolved, dir)
+	}
+	return resolved, nil
+}
+
+// isWithin reports whether dir equals parent or lies below it
+func isWithin(dir, parent string) bool {
+	return parent == "." || dir == parent || strings.HasPrefix(dir, parent+"/")
+}
+
+// Config returns the config used by the extractor
+func (e *Extractor) Config() *Config {
+	return e.cfg
+}
+
+// Scan walks the project directory and categorizes its files. When the extractor
+// was limited to paths or the config defines workspaces, only those directories
+// are scanned and their results are merged.
+func (e *Extractor) Scan(ctx context.Context) (*Result, error) {
+	e.status = Progress{Stage: StageScan}
+
+	switch {
+	case len(e.paths) > 0:
+		var results []*Result
+		for _, dir := range e.paths {
+			result, err := e.scanDir(ctx, dir, e.cfg)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		return mergeResults(results), nil
+
+	case len(e.cfg.Workspaces) > 0:
+		scans, err := e.scanWorkspaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return mergeResults(workspaceResults(scans)), nil
+
+	default:
+		return e.scanDir(ctx, "", e.cfg)
+	}
+}
+
+// scanDir scans a directory of the project and reports progress on top of the
+// previous scans of the run
+func (e *Extractor) scanDir(ctx context.Context, dir string, cfg *Config) (*Result, error) {
+	if e.progress == nil {
+		return scanner.ScanDir(ctx, e.projectPath, dir, cfg)
+	}
+
+	base, last := e.status, e.status
+	result, err := scanner.ScanDir(ctx, e.projectPath, dir, cfg, scanner.WithProgress(func(p scanner.Progress) {
+		last = base
+		last.Walked += p.Walked
+		last.Matched += p.Matched
+		last.Bytes += p.Bytes
+		last.Path = p.Path
+		e.progress(last)
+	}))
+	e.status = last
+	return result, err
+}
+
+// Render writes the markdown document for one category of a scan result to w
+func (e *Extractor) Render(ctx context.Context, w io.Writer, result *Result, category Category) error {
+	info, err := lookupCategory(category)
+	if err != nil {
+		return err
+	}
+
+	project, err := inspectProject(ctx, e.cfg, result)
+	if err != nil {
+		return err
+	}
+	return markdown.Render(ctx, w, document(e.cfg, info, result, project), e.cfg)
+}
+
+// formatDocuments returns the documents written by Generate for the configured format
+func formatDocuments(cfg *Config) []categoryInfo {
+	if cfg.OutputFormat() == config.FormatConsolidated {
+		return []categoryInfo{consolidated}
+	}
+	return categories
+}
+
+// Generate scans the project and writes one markdown document per category to
+// outputDir, or a single project.md in the consolidated format. Documents are
+// rendered to temporary files and only replace existing outputs once all of them
+// were written, so a cancelled run leaves previous documents untouched.
+//
+// When the config defines workspaces, every workspace gets its own set of documents
+// in a subdirectory of outputDir named after it, or with workspace_output set to
+// combined, a single set of documents with one section per workspace.
+func (e *Extractor) Generate(ctx context.Context, outputDir string) (*Summary, error) {
+	start := time.Now()
+	summary, err := e.generate(ctx, outputDir)
+	if err != nil {
+		return nil, err
+	}
+	summary.Duration = time.Since(start)
+	summary.OversizedPolicy = e.cfg.OversizedPolicy()
+	return summary, nil
+}
+
+// generate writes the documents of Generate
+func (e *Extractor) generate(ctx context.Context, outputDir string) (*Summary, error) {
+	if len(e.cfg.Workspaces) > 0 {
+		return e.generateWorkspaces(ctx, outputDir)
+	}
+
+	result, err := e.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	project, err := inspectProject(ctx, e.cfg, result)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []target
+	for _, info := range formatDocuments(e.cfg) {
+		targets = append(targets, newTarget(e.cfg, info, document(e.cfg, info, result, project), outputDir, ""))
+	}
+
+	summary := newSummary(result, project.files)
+	if err := e.writeTargets(ctx, targets, summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// workspaceScan is the scan result of a single workspace
+type workspaceScan struct {
+	workspace config.ResolvedWorkspace
+	result    *Result
+}
+
+// scanWorkspaces scans every workspace directory
+func (e *Extractor) scanWorkspaces(ctx context.Context) ([]workspaceScan, error) {
+	workspaces, err := e.cfg.ResolveWorkspaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspaces: %w", err)
+	}
+
+	scans := make([]workspaceScan, 0, len(workspaces))
+	for _, ws := range workspaces {
+		result, err := e.scanDir(ctx, ws.Dir, ws.Config)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("workspace %s: %w", ws.Name, err)
+		}
+		scans = append(scans, workspaceScan{workspace: ws, result: result})
+	}
+
+	return scans, nil
+}
+
+// generateWorkspaces writes the documents of a config with workspaces
+func (e *Extractor) generateWorkspaces(ctx context.Context, outputDir string) (*Summary, error) {
+	e.status = Progress{Stage: StageScan}
+	scans, err := e.scanWorkspaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := mergeResults(workspaceResults(scans))
+	project, err := inspectWorkspaces(ctx, e.cfg, scans)
+	if err != nil {
+		return nil, err
+	}
+	summary := newSummary(result, project.files)
+	var targets []target
+
+	for _, scan := range scans {
+		ws := scan.workspace
+		files := allFiles(scan.result)
+		size, _ := measureFiles(files, project.files, 0)
+		summary.Workspaces = append(summary.Workspaces, WorkspaceSummary{
+			Name:  ws.Name,
+			Dir:   ws.Dir,
+			Files: len(files),
+			Size:  size,
+		})
+
+		if e.cfg.WorkspaceMode() != config.WorkspacesSeparate {
+			continue
+		}
+		dir := filepath.Join(outputDir, filepath.FromSlash(ws.Name))
+		for _, info := range formatDocuments(ws.Config) {
+			targets = append(targets, newTarget(ws.Config, info, document(ws.Config, info, scan.result, project), dir, ws.Name))
+		}
+	}
+
+	if e.cfg.WorkspaceMode() == config.WorkspacesCombined {
+		for _, info := range formatDocuments(e.cfg) {
+			doc := document(e.cfg, info, result, project)
+			doc.Sections, doc.SectionKind = nil, "Workspace"
+			for _, scan := range scans {
+				doc.Sections = append(doc.Sections, markdown.Section{
+					Name:        scan.workspace.Name,
+					Dir:         scan.workspace.Dir,
+					Files:       Files(scan.result, info.category),
+					MaxFileSize: doc.MaxFileSize,
+				})
+			}
+			targets = append(targets, newTarget(e.cfg, info, doc, outputDir, ""))
+		}
+	}
+
+	if err := e.writeTargets(ctx, targets, summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// target is a document waiting to be written
+type target struct {
+	output Output
+	doc    markdown.Document
+	cfg    *Config
+}
+
+// newTarget prepares the document of a category for writing to dir
+func newTarget(cfg *Config, info categoryInfo, doc markdown.Document, dir, workspace string) target {
+	size, oversized := measureFiles(doc.Files, doc.Inspected, doc.MaxFileSize)
+	return target{
+		output: Output{
+			Category:  info.category,
+			Workspace: workspace,
+			Path:      filepath.Join(dir, info.filename),
+			Files:     len(doc.Files),
+			Size:      size,
+			Oversized: oversized,
+		},
+		doc: doc,
+		cfg: cfg,
+	}
+}
+
+// writeTargets renders all documents to temporary files and commits them once every
+// document was written. The outputs are added to summary.
+func (e *Extractor) writeTargets(ctx context.Context, targets []target, summary *Summary) error {
+	var pending []*atomicfile.File
+	defer func() {
+		for _, file := range pending {
+			file.Abort()
+		}
+	}()
+
+	status := e.status
+	status.Stage, status.Documents = StageWrite, len(targets)
+
+	for _, t := range targets {
+		name := filepath.Base(t.output.Path)
+		if e.progress != nil {
+			status.Path = t.output.Path
+			e.progress(status)
+			status.Written++
+		}
+
+		if err := os.MkdirAll(filepath.Dir(t.output.Path), 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+
+		file, err := atomicfile.Create(t.output.Path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		pending = append(pending, file)
+
+		if err := markdown.Render(ctx, file, t.doc, t.cfg); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+
+		summary.Outputs = append(summary.Outputs, t.output)
+		summary.TotalSize += t.output.Size
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := atomicfile.CommitAll(pending...); err != nil {
+		return err
+	}
+
+	if e.progress != nil {
+		status.Path = ""
+		e.progress(status)
+	}
+
+	return nil
+}
+
+// document builds the markdown document of a category. Context files, and scan
+// errors when the config asks for it, go to the code document. The consolidated
+// document has a section per category.
+func document(cfg *Config, info categoryInfo, result *Result, project *inspection) markdown.Document {
+	doc := markdown.Document{
+		Title:       info.title,
+		Files:       Files(result, info.category),
+		Links:       result.Links,
+		Inspected:   project.files,
+		Branch:      project.branch,
+		MaxFileSize: cfg.MaxFileSizeFor(string(info.category)),
+		Tree:        showTree(cfg, info.category),
+	}
+	if info.category != CategoryAll {
+		doc.Category = string(info.category)
+	}
+	if info.category == CategoryCode || info.category == CategoryAll {
+		doc.Context = result.Context
+		doc.GoAPI = cfg.ContextGoAPI
+		if cfg.ReportErrors {
+			doc.Errors = result.Errors
+		}
+	}
+
+	if info.category == CategoryAll {
+		for _, section := range categories {
+			doc.Sections = append(doc.Sections, markdown.Section{
+				Name:        section.title,
+				Files:       Files(result, section.category),
+				MaxFileSize: cfg.MaxFileSizeFor(string(section.category)),
+				Category:    string(section.category),
+			})
+		}
+	}
+	return doc
+}
+
+// showTree reports whether the document of a category starts with a project tree
+func showTree(cfg *Config, category Category) bool {
+	switch cfg.TreeMode() {
+	case config.TreeAll:
+		return true
+	case config.TreeConsolidated:
+		return category == CategoryAll
+	default:
+		return false
+	}
+}
+
+// inspectWorkspaces inspects the files of every workspace with the size limits of
+// its own config
+func inspectWorkspaces(ctx context.Context, cfg *Config, scans []workspaceScan) (*inspection, error) {
+	project := &inspection{files: make(map[string]language.File), branch: gitBranch(cfg.ProjectPath)}
+	for _, scan := range scans {
+		files, err := inspectFiles(ctx, scan.workspace.Config, scan.result)
+		if err != nil {
+			return nil, err
+		}
+		for file, info := range files {
+			project.files[file] = info
+		}
+	}
+	return project, nil
+}
+
+// workspaceResults returns the scan results of all workspaces
+func workspaceResults(scans []workspaceScan) []*Result {
+	results := make([]*Result, 0, len(scans))
+	for _, scan := range scans {
+		results = append(results, scan.result)
+	}
+	return results
+}
+
+// mergeResults combines the results of several scans
+func mergeResults(results []*Result) *Result {
+	merged := &Result{
+		Code:    []string{},
+		Data:    []string{},
+		Locals:  []string{},
+		Context: []string{},
+		Links:   map[string]scanner.Link{},
+
+		ExcludedBy: map[string]int{},
+	}
+	for _, result := range results {
+		merged.Code = append(merged.Code, result.Code...)
+		merged.Data = append(merged.Data, result.Data...)
+		merged.Locals = append(merged.Locals, result.Locals...)
+		merged.Context = append(merged.Context, result.Context...)
+		merged.Total += result.Total
+		merged.Excluded += result.Excluded
+		for rule, count := range result.ExcludedBy {
+			merged.ExcludedBy[rule] += count
+		}
+		merged.Errors = append(merged.Errors, result.Errors...)
+		merged.SkippedLinks = append(merged.SkippedLinks, result.SkippedLinks...)
+		for path, link := range result.Links {
+			merged.Links[path] = link
+		}
+	}
+	return merged
+}
+
+// allFiles returns the files of every category of a scan result
+func allFiles(result *Result) []string {
+	files := []string{}
+	for _, info := range categories {
+		files = append(files, Files(result, info.category)...)
+	}
+	return files
+}
+
+// Files returns the files of a scan result that belong to a category
+func Files(result *Result, category Category) []string {
+	switch category {
+	case CategoryAll:
+		return allFiles(result)
+	case CategoryCode:
+		return result.Code
+	case CategoryData:
+		return result.Data
+	case CategoryLocals:
+		return result.Locals
+	case CategoryContext:
+		return result.Context
+	default:
+		return nil
+	}
+}
+
+// lookupCategory returns the document description of a category
+func lookupCategory(category Category) (categoryInfo, error) {
+	if category == CategoryAll {
+		return consolidated, nil
+	}
+	for _, info := range categories {
+		if info.category == category {
+			return info, nil
+		}
+	}
+	return categoryInfo{}, fmt.Errorf("unknown category: %s", category)
+}
+
+// measureFiles calculates the total size of a list of inspected files and counts
+// the files larger than limit (when limit is positive)
+func measureFiles(files []string, inspected map[string]language.File, limit int64) (int64, int) {
+	size, oversized := int64(0), 0
+	for _, file := range files {
+		bytes := inspected[file].Bytes
+		size += bytes
+		if limit > 0 && bytes > limit {
+			oversized++
+		}
+	}
+	return size, oversized
+}
@@ -0,0 +1,241 @@
+package extract
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adil-chbada/extract-cli/internal/language"
+)
+
+// EstimateTokens returns a rough estimate of the number of tokens in size bytes,
+// assuming 4 bytes per token as usual for English text and source code
+func EstimateTokens(size int64) int64 {
+	return language.EstimateTokens(size)
+}
+
+// LineCounts counts total, code, comment and blank lines. Code, comment and blank
+// lines are only counted for languages with a known comment syntax.
+type LineCounts = language.Lines
+
+// Counts aggregates a group of files
+type Counts struct {
+	Files     int        `json:"files"`
+	Bytes     int64      `json:"bytes"`
+	Lines     LineCounts `json:"lines"`
+	Tokens    int64      `json:"tokens"`              // Estimated with EstimateTokens
+	Uncounted int        `json:"uncounted,omitempty"` // Files above max_file_size, whose lines are not counted
+}
+
+// add counts a file
+func (c *Counts) add(file language.File) {
+	c.Files++
+	c.Bytes += file.Bytes
+	c.Lines.Add(file.Lines)
+	c.Tokens = EstimateTokens(c.Bytes)
+	if file.Uncounted {
+		c.Uncounted++
+	}
+}
+
+// merge adds the counts of another group
+func (c *Counts) merge(other Counts) {
+	c.Files += other.Files
+	c.Bytes += other.Bytes
+	c.Lines.Add(other.Lines)
+	c.Tokens = EstimateTokens(c.Bytes)
+	c.Uncounted += other.Uncounted
+}
+
+// Stats is the machine readable summary of a run
+type Stats struct {
+	Project     string    `json:"project"`
+	GeneratedAt time.Time `json:"generated_at"`
+	DurationMS  int64     `json:"duration_ms"`
+	ConfigHash  string    `json:"config_hash"`
+
+	Total       Counts                       `json:"total"`
+	Categories  map[Category]Counts          `json:"categories"`
+	Directories map[string]Counts            `json:"directories"`
+	Extensions  map[string]Counts            `json:"extensions"` // Keyed by lower-case extension, "(none)" for files without one
+	Languages   map[string]Counts            `json:"languages"`
+	LanguageMix map[string]map[string]Counts `json:"language_mix"` // Languages of each directory
+
+	Scanned    int            `json:"scanned"`     // Files and directories visited
+	Excluded   int            `json:"excluded"`    // Files excluded by any rule
+	ExcludedBy map[string]int `json:"excluded_by"` // Excluded files per pattern, .gitignore or rule
+	Errors     int            `json:"errors"`      // Paths that could not be read
+
+	Outputs []string `json:"outputs,omitempty"` // Written documents
+}
+
+// Stats computes the statistics of a Generate run from the files inspected during
+// the run
+func (e *Extractor) Stats(summary *Summary) *Stats {
+	inspected := summary.inspected
+	if inspected == nil {
+		inspected, _ = inspectFiles(context.Background(), e.cfg, summary.Result)
+	}
+	stats := e.resultStats(summary.Result, inspected)
+	stats.DurationMS = summary.Duration.Milliseconds()
+	for _, output := range summary.Outputs {
+		stats.Outputs = append(stats.Outputs, output.Path)
+	}
+	return stats
+}
+
+// ResultStats computes the statistics of a scan result. Every listed file is read
+// to count its lines and detect its language, except files above max_file_size.
+func (e *Extractor) ResultStats(result *Result) *Stats {
+	inspected, _ := inspectFiles(context.Background(), e.cfg, result)
+	return e.resultStats(result, inspected)
+}
+
+// resultStats computes the statistics of a scan result from its inspected files
+func (e *Extractor) resultStats(result *Result, inspected map[string]language.File) *Stats {
+	stats := &Stats{
+		Project:     projectName(e.cfg),
+		GeneratedAt: time.Now().UTC(),
+		ConfigHash:  e.cfg.Hash(),
+		Categories:  make(map[Category]Counts),
+		Directories: make(map[string]Counts),
+		Extensions:  make(map[string]Counts),
+		Languages:   make(map[string]Counts),
+		LanguageMix: make(map[string]map[string]Counts),
+		Scanned:     result.Total,
+		Excluded:    result.Excluded,
+		ExcludedBy:  result.ExcludedBy,
+		Errors:      len(result.Errors),
+	}
+
+	for _, category := range countedCategories() {
+		counts := Counts{}
+		for _, rel := range Files(result, category) {
+			file := inspected[rel]
+			counts.add(file)
+			stats.Total.add(file)
+			addTo(stats.Directories, dirOf(rel), file)
+			addTo(stats.Extensions, extensionOf(rel), file)
+			addTo(stats.Languages, file.Language, file)
+
+			mix, ok := stats.LanguageMix[dirOf(rel)]
+			if !ok {
+				mix = make(map[string]Counts)
+				stats.LanguageMix[dirOf(rel)] = mix
+			}
+			addTo(mix, file.Language, file)
+		}
+		stats.Categories[category] = counts
+	}
+
+	return stats
+}
+
+// LanguageMixAt returns the languages of the directories depth levels below the
+// project root, with the files of deeper directories counted in their ancestor.
+// Files above that depth are counted in their own directory.
+func (s *Stats) LanguageMixAt(depth int) map[string]map[string]Counts {
+	mixes := make(map[string]map[string]Counts)
+	for dir, mix := range s.LanguageMix {
+		key := dir
+		if depth > 0 && dir != "." {
+			if parts := strings.Split(dir, "/"); len(parts) > depth {
+				key = strings.Join(parts[:depth], "/")
+			}
+		}
+
+		target, ok := mixes[key]
+		if !ok {
+			target = make(map[string]Counts)
+			mixes[key] = target
+		}
+		for lang, counts := range mix {
+			merged := target[lang]
+			merged.merge(counts)
+			target[lang] = merged
+		}
+	}
+	return mixes
+}
+
+// addTo counts a file in the group key of counts
+func addTo(counts map[string]Counts, key string, file language.File) {
+	c := counts[key]
+	c.add(file)
+	counts[key] = c
+}
+
+// dirOf returns the directory of a project file, "." for the project root
+func dirOf(file string) string {
+	return path.Dir(file)
+}
+
+// extensionOf returns the lower-case extension of a file without the dot, or
+// "(none)"
+func extensionOf(file string) string {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(file), "."))
+	if ext == "" {
+		return "(none)"
+	}
+	return ext
+}
+
+// inspection holds what is read from the project once per scan
+type inspection struct {
+	files  map[string]language.File // Listed files with their language and line counts
+	branch string                   // Checked out git branch of the project, if any
+}
+
+// inspectProject inspects the listed files of a scan result and looks up the git
+// branch of the project
+func inspectProject(ctx context.Context, cfg *Config, result *Result) (*inspection, error) {
+	files, err := inspectFiles(ctx, cfg, result)
+	if err != nil {
+		return nil, err
+	}
+	return &inspection{files: files, branch: gitBranch(cfg.ProjectPath)}, nil
+}
+
+// inspectFiles reads every listed file of a scan result once to detect its
+// language and count its lines. Files above the max_file_size of their category
+// are not read past their first bytes; only their size and language are reported.
+// It stops when ctx is cancelled.
+func inspectFiles(ctx context.Context, cfg *Config, result *Result) (map[string]language.File, error) {
+	inspected := make(map[string]language.File)
+	for _, category := range countedCategories() {
+		// Context files are embedded in the code document, with its limit
+		limitCategory := category
+		if category == CategoryContext {
+			limitCategory = CategoryCode
+		}
+		limit := cfg.MaxFileSizeFor(string(limitCategory))
+		for _, file := range Files(result, category) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			inspected[file] = inspect(cfg.ProjectPath, result, file, limit)
+		}
+	}
+	return inspected, nil
+}
+
+// inspect reads a project file up to limit bytes, see language.InspectUpTo. Files
+// that cannot be read, and links whose target is not read, are counted with what
+// is known from their name.
+func inspect(projectPath string, result *Result, file string, limit int64) language.File {
+	if link, ok := result.Links[file]; ok && link.ListedOnly() {
+		return language.File{Language: language.Detect(file)}
+	}
+	info, _ := language.InspectUpTo(filepath.Join(projectPath, filepath.FromSlash(file)), limit)
+	return info
+}
+
+// projectName returns the configured project name or the project directory name
+func projectName(cfg *Config) string {
+	if cfg.ProjectName != "" {
+		return cfg.ProjectName
+	}
+	return filepath.Base(cfg.ProjectPath)
+}
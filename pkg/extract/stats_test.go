@@ -0,0 +1,48 @@
+package extract
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+)
+
+func TestOversizedFilesAreNotCounted(t *testing.T) {
+	project := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(project, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(project, "main.go"), "package main\n\nfunc main() {}\n")
+	big := strings.Repeat("{\"row\": 1}\n", 100)
+	writeFile(t, filepath.Join(project, "data", "rows.json"), big)
+
+	cfg := &Config{
+		ProjectPath:            project,
+		DataPatterns:           []string{"data/**"},
+		MaxFileSizePerCategory: map[string]config.ByteSize{"data": 64},
+	}
+	if err := cfg.ApplyDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	ex, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ex.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := ex.ResultStats(result)
+
+	data := stats.Categories[CategoryData]
+	if data.Bytes != int64(len(big)) || data.Lines.Total != 0 || data.Uncounted != 1 {
+		t.Errorf("data counts = %+v, want the size of rows.json without its lines", data)
+	}
+	code := stats.Categories[CategoryCode]
+	if code.Lines.Total != 3 || code.Uncounted != 0 {
+		t.Errorf("code counts = %+v, want the 3 lines of main.go", code)
+	}
+}
@@ -0,0 +1,94 @@
+package extract
+
+import (
+	"time"
+
+	"github.com/adil-chbada/extract-cli/internal/language"
+)
+
+// Output describes a generated document
+type Output struct {
+	Category  Category
+	Workspace string // Workspace the document belongs to, empty for project-wide documents
+	Path      string
+	Files     int
+	Size      int64 // Total size of the listed files
+	// Number of files above the max_file_size limit of the category
+	Oversized int
+}
+
+// WorkspaceSummary describes the files found in a workspace
+type WorkspaceSummary struct {
+	Name  string
+	Dir   string // Directory relative to the project path
+	Files int
+	Size  int64
+}
+
+// Summary describes the outcome of Generate
+type Summary struct {
+	Result        *Result
+	Outputs       []Output
+	Workspaces    []WorkspaceSummary
+	CategorySizes map[Category]int64      // Total size of the files of each category
+	CategoryLines map[Category]LineCounts // Lines of the files of each category
+	TotalSize     int64
+	TotalLines    LineCounts
+	Duration      time.Duration // Time spent scanning and writing
+	// How the contents of files above max_file_size were handled: skip or truncate
+	OversizedPolicy string
+
+	inspected map[string]language.File // Listed files, inspected once per scan
+}
+
+// newSummary creates the summary of a scan result from its inspected files
+func newSummary(result *Result, inspected map[string]language.File) *Summary {
+	summary := &Summary{
+		Result:        result,
+		CategorySizes: make(map[Category]int64),
+		CategoryLines: make(map[Category]LineCounts),
+		inspected:     inspected,
+	}
+	for _, category := range countedCategories() {
+		var lines LineCounts
+		for _, file := range Files(result, category) {
+			lines.Add(inspected[file].Lines)
+		}
+		summary.CategorySizes[category], _ = measureFiles(Files(result, category), inspected, 0)
+		summary.CategoryLines[category] = lines
+		summary.TotalLines.Add(lines)
+	}
+	return summary
+}
+
+// CategorySummary describes the files of a category
+type CategorySummary struct {
+	Category Category
+	Files    int
+	Size     int64
+	Lines    LineCounts
+}
+
+// Categories returns the summary of the code, data, locals and context files
+func (s *Summary) Categories() []CategorySummary {
+	var categories []CategorySummary
+	for _, category := range countedCategories() {
+		categories = append(categories, CategorySummary{
+			Category: category,
+			Files:    len(Files(s.Result, category)),
+			Size:     s.CategorySizes[category],
+			Lines:    s.CategoryLines[category],
+		})
+	}
+	return categories
+}
+
+// Oversized returns the number of listed files above the max_file_size limit of
+// their document
+func (s *Summary) Oversized() int {
+	oversized := 0
+	for _, output := range s.Outputs {
+		oversized += output.Oversized
+	}
+	return oversized
+}
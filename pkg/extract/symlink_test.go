@@ -0,0 +1,128 @@
+package extract
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adil-chbada/extract-cli/internal/config"
+)
+
+// symlinkProject creates a project with a regular file and links to a file
+// outside the project, a directory and a missing file
+func symlinkProject(t *testing.T) (string, *Config) {
+	t.Helper()
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{project, outside, filepath.Join(project, "pkg")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(outside, "secret.txt"), "TOP-SECRET\n")
+	writeFile(t, filepath.Join(project, "main.go"), "package main\n")
+	writeFile(t, filepath.Join(project, "pkg", "lib.go"), "package pkg\n")
+
+	links := map[string]string{
+		"secret.txt": filepath.Join(outside, "secret.txt"),
+		"pkglink":    "pkg",
+		"missing.go": "nowhere.go",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(project, name)); err != nil {
+			t.Skipf("symlinks are not supported: %v", err)
+		}
+	}
+
+	cfg := &Config{ProjectPath: project, IncludeContents: true}
+	if err := cfg.ApplyDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	return project, cfg
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListedLinksAreNotRead(t *testing.T) {
+	_, cfg := symlinkProject(t)
+	cfg.Symlinks = config.SymlinksList
+
+	ex, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	summary, err := ex.Generate(context.Background(), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "project-code.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(data)
+
+	if strings.Contains(doc, "TOP-SECRET") {
+		t.Errorf("contents of a link outside the project were embedded:\n%s", doc)
+	}
+	if strings.Contains(doc, "Contents unavailable") {
+		t.Errorf("contents of a directory link were read:\n%s", doc)
+	}
+	for _, want := range []string{
+		"- `pkglink` **(symlink)**",
+		"🔗 → `pkg` *(directory)*",
+		"- `secret.txt` **(symlink)**",
+		"*(outside project)*",
+		"- `missing.go` **(symlink)**",
+		"*(broken link)*",
+		"symlink target is outside the project",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document is missing %q:\n%s", want, doc)
+		}
+	}
+
+	// Only the regular files are measured
+	want := int64(len("package main\n") + len("package pkg\n"))
+	if summary.TotalSize != want {
+		t.Errorf("TotalSize = %d, want %d", summary.TotalSize, want)
+	}
+	if len(summary.Result.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", summary.Result.Errors)
+	}
+}
+
+func TestFollowSkipsEscapingAndBrokenLinks(t *testing.T) {
+	_, cfg := symlinkProject(t)
+	cfg.Symlinks = config.SymlinksFollow
+
+	ex, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ex.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", result.Errors)
+	}
+	skipped := strings.Join(result.SkippedLinks, ",")
+	if skipped != "missing.go,secret.txt" {
+		t.Errorf("SkippedLinks = %q, want missing.go,secret.txt", skipped)
+	}
+	code := strings.Join(result.Code, ",")
+	if !strings.Contains(code, "pkglink/lib.go") || strings.Contains(code, "secret.txt") {
+		t.Errorf("Code = %q, want the followed directory link and no escaping link", code)
+	}
+}